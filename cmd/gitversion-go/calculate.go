@@ -12,10 +12,18 @@ import (
 
 var outputFormat string
 var targetPath string
+var project string
+var allProjects bool
+var explain bool
 
 func init() {
-	calculateCmd.Flags().StringVar(&outputFormat, "output", "default", "Output format (default, json)")
+	calculateCmd.Flags().StringVar(&outputFormat, "output", "default", "Output format (default, json, go, oci)")
 	calculateCmd.Flags().StringVar(&targetPath, "path", ".", "The path to the Git repository.")
+	calculateCmd.Flags().StringVar(&project, "project", "", "Calculate the version of a single project from the projects config section.")
+	calculateCmd.Flags().BoolVar(&allProjects, "all-projects", false, "Calculate every project from the projects config section and print a JSON array.")
+	calculateCmd.Flags().BoolVar(&explain, "explain", false, "Explain how the version was calculated, as a tree or as JSON with --output json.")
+	calculateCmd.MarkFlagsMutuallyExclusive("project", "all-projects")
+	calculateCmd.MarkFlagsMutuallyExclusive("explain", "all-projects")
 	rootCmd.AddCommand(calculateCmd)
 }
 
@@ -24,7 +32,8 @@ var calculateCmd = &cobra.Command{
 	Short: "Calculates the next version from the Git repository",
 	Run: func(_ *cobra.Command, _ []string) {
 		fileSystem := fs.NewOsFs()
-		if err := app.RunCalculate(fileSystem, os.Stdout, targetPath, outputFormat); err != nil {
+		opts := app.CalculateOptions{OutputFormat: outputFormat, Project: project, AllProjects: allProjects, Explain: explain}
+		if err := app.RunCalculateWithOptions(fileSystem, os.Stdout, targetPath, opts); err != nil {
 			log.Fatal(err)
 		}
 	},
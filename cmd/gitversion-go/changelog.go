@@ -0,0 +1,30 @@
+package main
+
+import (
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var changelogOpts app.ChangelogOptions
+var changelogPath string
+
+func init() {
+	changelogCmd.Flags().StringVar(&changelogOpts.From, "from", "", "Start of the range: a released version (default: the base version)")
+	changelogCmd.Flags().StringVar(&changelogOpts.To, "to", "", "End of the range: a released version (default: HEAD)")
+	changelogCmd.Flags().StringVar(&changelogOpts.Template, "template", "", "File holding a Go template for the notes, relative to the repository")
+	changelogCmd.Flags().StringVar(&changelogOpts.Project, "project", "", "Render the notes of a single project from the projects config section.")
+	changelogCmd.Flags().StringVar(&changelogOpts.Update, "update", "", "Add the notes to this Keep a Changelog file relative to the repository, e.g. CHANGELOG.md, instead of printing them")
+	changelogCmd.Flags().StringVar(&changelogPath, "path", ".", "The path to the Git repository.")
+	rootCmd.AddCommand(changelogCmd)
+}
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Renders Markdown release notes from the commits since the last version",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return app.RunChangelog(fs.NewOsFs(), os.Stdout, changelogPath, changelogOpts)
+	},
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var configPath string
+var configBranch string
+
+func init() {
+	configCmd.PersistentFlags().StringVar(&configPath, "path", ".", "The path to the Git repository.")
+	configShowCmd.Flags().StringVar(&configBranch, "branch", "", "Resolve the configuration for this branch instead of the current one.")
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspects the GitVersion.yml configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Prints the configuration resolved for a branch, with the source of each setting",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return app.RunConfigShow(fs.NewOsFs(), os.Stdout, configPath, configBranch)
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Checks GitVersion.yml for unknown keys and invalid values, reporting their line and column",
+	Args:  cobra.NoArgs,
+	// The problems found are the output; usage would only hide them.
+	SilenceUsage: true,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return app.RunConfigValidate(fs.NewOsFs(), os.Stdout, configPath)
+	},
+}
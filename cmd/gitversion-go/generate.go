@@ -0,0 +1,29 @@
+package main
+
+import (
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var generateOpts app.GenerateOptions
+var generatePath string
+
+func init() {
+	generateCmd.Flags().StringVar(&generateOpts.Lang, "lang", "go", "Kind of file to write: go, json or VERSION")
+	generateCmd.Flags().StringVar(&generateOpts.Out, "out", "", "The file to write, relative to the repository (default version_gen.go, version.json or VERSION)")
+	generateCmd.Flags().StringVar(&generateOpts.Package, "package", "", "Go package name (default: the name of the output directory)")
+	generateCmd.Flags().StringVar(&generateOpts.Project, "project", "", "Use the version of a single project from the projects config section.")
+	generateCmd.Flags().StringVar(&generatePath, "path", ".", "The path to the Git repository.")
+	rootCmd.AddCommand(generateCmd)
+}
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Writes the calculated version to a Go, JSON or VERSION file",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return app.RunGenerate(fs.NewOsFs(), os.Stdout, generatePath, generateOpts)
+	},
+}
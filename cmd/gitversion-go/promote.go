@@ -0,0 +1,34 @@
+package main
+
+import (
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var promoteOpts app.PromoteOptions
+var promotePath string
+
+func init() {
+	promoteCmd.Flags().BoolVar(&promoteOpts.Annotated, "annotate", false, "Create an annotated tag, with the message from the tag.message template.")
+	promoteCmd.Flags().StringVar(&promoteOpts.Remote, "remote", "", "Push the new tag to this remote, e.g. origin.")
+	promoteCmd.Flags().StringVar(&promoteOpts.Project, "project", "", "Promote a pre-release of a single project from the projects config section.")
+	promoteCmd.Flags().StringVar(&promoteOpts.SigningKey, "sign-key", "", "Sign the tag with this key: a file relative to --path, or a key ID for --sign-format gpg. An encrypted OpenPGP key file is unlocked with $GITVERSION_SIGNING_PASSPHRASE.")
+	promoteCmd.Flags().StringVar(&promoteOpts.SigningFormat, "sign-format", "", "How to sign the tag: openpgp (an armored private key file, the default), gpg (through gpg-agent) or ssh (a key file, or a public key file with ssh-agent).")
+	promoteCmd.Flags().BoolVar(&promoteOpts.Changelog, "changelog", false, "Print the release notes of the final version, gathering all its pre-releases.")
+	promoteCmd.Flags().StringVar(&promoteOpts.Update, "update", "", "Add the release notes to this Keep a Changelog file relative to the repository, e.g. CHANGELOG.md")
+	promoteCmd.Flags().StringVar(&promotePath, "path", ".", "The path to the Git repository.")
+	rootCmd.AddCommand(promoteCmd)
+}
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote <pre-release version>",
+	Short: "Tags the commit of a pre-release with its final version",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		promoteOpts.Passphrase = os.Getenv("GITVERSION_SIGNING_PASSPHRASE")
+		return app.RunPromote(fs.NewOsFs(), os.Stdout, promotePath, args[0], promoteOpts)
+	},
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var tagOpts app.TagOptions
+var tagPath string
+
+func init() {
+	tagCmd.Flags().BoolVar(&tagOpts.Annotated, "annotate", false, "Create an annotated tag, with the message from the tag.message template.")
+	tagCmd.Flags().StringVar(&tagOpts.Remote, "remote", "", "Push the new tag to this remote, e.g. origin.")
+	tagCmd.Flags().StringVar(&tagOpts.Project, "project", "", "Tag the version of a single project from the projects config section.")
+	tagCmd.Flags().StringVar(&tagOpts.SigningKey, "sign-key", "", "Sign the tag with this key: a file relative to --path, or a key ID for --sign-format gpg. An encrypted OpenPGP key file is unlocked with $GITVERSION_SIGNING_PASSPHRASE.")
+	tagCmd.Flags().StringVar(&tagOpts.SigningFormat, "sign-format", "", "How to sign the tag: openpgp (an armored private key file, the default), gpg (through gpg-agent) or ssh (a key file, or a public key file with ssh-agent).")
+	tagCmd.Flags().StringVar(&tagPath, "path", ".", "The path to the Git repository.")
+	rootCmd.AddCommand(tagCmd)
+}
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Tags HEAD with the calculated version",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		tagOpts.Passphrase = os.Getenv("GITVERSION_SIGNING_PASSPHRASE")
+		return app.RunTag(fs.NewOsFs(), os.Stdout, tagPath, tagOpts)
+	},
+}
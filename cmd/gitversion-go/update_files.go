@@ -0,0 +1,27 @@
+package main
+
+import (
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var updateFilesOpts app.UpdateFilesOptions
+var updateFilesPath string
+
+func init() {
+	updateFilesCmd.Flags().BoolVar(&updateFilesOpts.DryRun, "dry-run", false, "Print a diff instead of writing the files.")
+	updateFilesCmd.Flags().StringVar(&updateFilesOpts.Project, "project", "", "Use the version of a single project from the projects config section.")
+	updateFilesCmd.Flags().StringVar(&updateFilesPath, "path", ".", "The path to the Git repository.")
+	rootCmd.AddCommand(updateFilesCmd)
+}
+
+var updateFilesCmd = &cobra.Command{
+	Use:   "update-files",
+	Short: "Sets the calculated version in package.json, Chart.yaml, Cargo.toml, pom.xml, pyproject.toml and configured files",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return app.RunUpdateFiles(fs.NewOsFs(), os.Stdout, updateFilesPath, updateFilesOpts)
+	},
+}
@@ -14,7 +14,6 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5"
-	"gopkg.in/yaml.v3"
 )
 
 // RunInit creates a GitVersion.yml config file for the given workflow.
@@ -45,40 +44,75 @@ func RunInit(fsys fs.Filesystem, workflow string) error {
 	return nil
 }
 
+// CalculateOptions holds the settings of the calculate command.
+type CalculateOptions struct {
+	OutputFormat string
+	// Project restricts the calculation to one project from the projects config section.
+	Project string
+	// AllProjects calculates every configured project and prints a JSON array.
+	AllProjects bool
+	// Explain prints the decisions behind the version: as a tree, or as JSON
+	// with the json output format.
+	Explain bool
+}
+
+// ProjectVersion is one element of the --all-projects output. Changed is set
+// when the project has a version that has not been released yet, and BumpedBy
+// names the changed projects it depends on through go.mod, if that is why.
+type ProjectVersion struct {
+	Name      string           `json:"Name"`
+	Path      string           `json:"Path"`
+	Changed   bool             `json:"Changed"`
+	BumpedBy  []string         `json:"BumpedBy,omitempty"`
+	Variables VersionVariables `json:"Variables"`
+}
+
 // RunCalculate calculates the next version and writes output to the writer.
 func RunCalculate(fsys fs.Filesystem, out io.Writer, path, outputFormat string) error {
-	var config gitversion.Config
-	configPath := filepath.Join(path, "GitVersion.yml")
+	return RunCalculateWithOptions(fsys, out, path, CalculateOptions{OutputFormat: outputFormat})
+}
 
-	data, err := fsys.ReadFile(configPath)
-	if err == nil {
-		err = yaml.Unmarshal(data, &config)
-		if err != nil {
-			return fmt.Errorf("failed to parse GitVersion.yml: %w", err)
+// RunCalculateWithOptions calculates the next version per the options and writes output to the writer.
+func RunCalculateWithOptions(fsys fs.Filesystem, out io.Writer, path string, opts CalculateOptions) error {
+	config, r, branchName, err := openRepository(fsys, path)
+	if err != nil {
+		return err
+	}
+	if opts.AllProjects {
+		if opts.Explain {
+			return fmt.Errorf("--explain cannot be combined with --all-projects")
 		}
-	} else if !os.IsNotExist(err) {
-		return fmt.Errorf("failed to read GitVersion.yml: %w", err)
+		return writeAllProjects(out, r, config, branchName)
 	}
 
-	r, err := git.PlainOpen(path)
-	if err != nil {
-		return fmt.Errorf("failed to open repository at %s: %w", path, err)
+	calculate := calculateProject
+	if opts.Explain {
+		calculate = explainProject
 	}
-
-	head, err := r.Head()
+	result, err := calculate(r, config, branchName, opts.Project)
 	if err != nil {
-		return fmt.Errorf("failed to get HEAD: %w", err)
+		return err
 	}
-
-	branchName := head.Name().Short()
-	nextVersion, commitsSinceTag, err := gitversion.CalculateNextVersion(r, &config, branchName)
-	if err != nil {
-		return fmt.Errorf("failed to calculate next version: %w", err)
+	vars := buildVersionVariables(result)
+	if opts.Explain {
+		if opts.OutputFormat == "json" {
+			jsonOutput, err := json.Marshal(result.Trace)
+			if err != nil {
+				return fmt.Errorf("failed to generate JSON output: %w", err)
+			}
+			_, err = fmt.Fprintln(out, string(jsonOutput))
+			return err
+		}
+		if err := writeTrace(out, result.Trace); err != nil {
+			return err
+		}
 	}
 
-	vars := buildVersionVariables(nextVersion, branchName, commitsSinceTag, &config)
-
-	switch outputFormat {
+	switch opts.OutputFormat {
+	case "go":
+		return writeGoOutput(out, result, vars)
+	case "oci":
+		return writeOCIOutput(out, result, vars)
 	case "json":
 		jsonOutput, err := json.Marshal(vars)
 		if err != nil {
@@ -88,6 +122,16 @@ func RunCalculate(fsys fs.Filesystem, out io.Writer, path, outputFormat string)
 			return err
 		}
 	default:
+		for _, warning := range vars.Warnings {
+			if _, err := fmt.Fprintf(out, "Warning: %s\n", warning); err != nil {
+				return err
+			}
+		}
+		for _, symbol := range vars.APIIncompatible {
+			if _, err := fmt.Fprintf(out, "Incompatible API change: %s\n", symbol); err != nil {
+				return err
+			}
+		}
 		if _, err := fmt.Fprintf(out, "Calculated next version: %s\n", vars.FullSemVer); err != nil {
 			return err
 		}
@@ -95,6 +139,143 @@ func RunCalculate(fsys fs.Filesystem, out io.Writer, path, outputFormat string)
 	return nil
 }
 
+// writeGoOutput prints an -ldflags argument that sets the configured version and
+// commit variables, followed by the Go module pseudo-version of HEAD.
+func writeGoOutput(out io.Writer, result *gitversion.VersionContext, vars VersionVariables) error {
+	pseudoVersion, err := gitversion.PseudoVersion(result)
+	if err != nil {
+		return fmt.Errorf("failed to build pseudo-version: %w", err)
+	}
+	ldflags := result.Config.LDFlags
+	_, err = fmt.Fprintf(out, "-ldflags \"-X %s=%s -X %s=%s\"\n%s\n",
+		ldflags.VersionVar(), vars.FullSemVer, ldflags.CommitVar(), vars.Sha, pseudoVersion)
+	return err
+}
+
+// writeAllProjects calculates every configured project and writes them as a JSON
+// array, which CI systems can use directly as a build matrix.
+func writeAllProjects(out io.Writer, r *git.Repository, config *gitversion.Config, branchName string) error {
+	if len(config.Projects) == 0 && !config.GoModules {
+		return fmt.Errorf("no projects configured")
+	}
+
+	results, err := gitversion.CalculateAllProjects(r, config, branchName)
+	if err != nil {
+		return fmt.Errorf("failed to calculate next version: %w", err)
+	}
+
+	projects := make([]ProjectVersion, 0, len(results))
+	for _, result := range results {
+		projects = append(projects, ProjectVersion{
+			Name:      result.ProjectName,
+			Path:      result.ProjectPath,
+			Changed:   result.Changed(),
+			BumpedBy:  result.BumpedBy,
+			Variables: buildVersionVariables(result),
+		})
+	}
+
+	jsonOutput, err := json.Marshal(projects)
+	if err != nil {
+		return fmt.Errorf("failed to generate JSON output: %w", err)
+	}
+	_, err = fmt.Fprintln(out, string(jsonOutput))
+	return err
+}
+
+// openRepository loads the configuration and opens the repository at path,
+// returning the name of the branch HEAD points at.
+func openRepository(fsys fs.Filesystem, path string) (*gitversion.Config, *git.Repository, string, error) {
+	config, err := loadConfig(fsys, path)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to open repository at %s: %w", path, err)
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	return config, r, head.Name().Short(), nil
+}
+
+// calculateProject calculates the named project, or the whole repository when
+// project is empty.
+func calculateProject(r *git.Repository, config *gitversion.Config, branchName, project string) (*gitversion.VersionContext, error) {
+	var result *gitversion.VersionContext
+	var err error
+	if project != "" {
+		result, err = gitversion.CalculateProject(r, config, branchName, project)
+	} else {
+		result, err = gitversion.Calculate(r, config, branchName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate next version: %w", err)
+	}
+	return result, nil
+}
+
+// explainProject is calculateProject recording the decisions of the calculation
+// in result.Trace.
+func explainProject(r *git.Repository, config *gitversion.Config, branchName, project string) (*gitversion.VersionContext, error) {
+	result, err := gitversion.Explain(r, config, branchName, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate next version: %w", err)
+	}
+	return result, nil
+}
+
+// loadConfig validates GitVersion.yml from the repository root, if there is one,
+// layers it over the built-in defaults, and reads the trusted keys it refers to.
+func loadConfig(fsys fs.Filesystem, path string) (*gitversion.Config, error) {
+	configPath := filepath.Join(path, "GitVersion.yml")
+
+	data, err := fsys.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read GitVersion.yml: %w", err)
+	}
+	if errs := gitversion.ValidateConfig("GitVersion.yml", data); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errs)
+	}
+	config, err := gitversion.LoadConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitVersion.yml: %w", err)
+	}
+
+	if config.TrustedKeys != "" {
+		keys, err := fsys.ReadFile(repositoryFile(path, config.TrustedKeys))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trusted keys: %w", err)
+		}
+		if err := config.SetTrustedKeys(keys); err != nil {
+			return nil, err
+		}
+	}
+	if config.TrustedSSHKeys != "" {
+		file := repositoryFile(path, config.TrustedSSHKeys)
+		if exists, err := fsys.Exists(file); err != nil {
+			return nil, fmt.Errorf("failed to read trusted SSH keys: %w", err)
+		} else if !exists {
+			return nil, fmt.Errorf("failed to read trusted SSH keys: %s does not exist", file)
+		}
+		config.SetTrustedSSHKeys(file)
+	}
+	return config, nil
+}
+
+// repositoryFile resolves a path from the configuration, which is relative to the
+// repository root unless it is absolute.
+func repositoryFile(root, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(root, name)
+}
+
 // VersionVariables holds version information for output formats.
 type VersionVariables struct {
 	Major         string `json:"Major"`
@@ -102,11 +283,68 @@ type VersionVariables struct {
 	Patch         string `json:"Patch"`
 	PreReleaseTag string `json:"PreReleaseTag"`
 	FullSemVer    string `json:"FullSemVer"`
+	// Sha is the SHA of HEAD and CommitDate its commit date, formatted per commit-date-format.
+	Sha        string `json:"Sha,omitempty"`
+	CommitDate string `json:"CommitDate,omitempty"`
+	// The version formatted for ecosystems whose version rules differ from semver.
+	PEP440      string `json:"PEP440"`
+	Debian      string `json:"Debian"`
+	RPMVersion  string `json:"RPMVersion"`
+	RPMRelease  string `json:"RPMRelease"`
+	NuGetLegacy string `json:"NuGetLegacy"`
+	Maven       string `json:"Maven"`
+	DockerTag   string `json:"DockerTag"`
+	// ReleaseAsSource and SemverLabelSource hold the SHA of the commit whose
+	// trailer overrode the version or the pre-release label, if any.
+	ReleaseAsSource   string `json:"ReleaseAsSource,omitempty"`
+	SemverLabelSource string `json:"SemverLabelSource,omitempty"`
+	// CancelledReverts lists commits left out of bump analysis because a later
+	// commit in the same range reverted them.
+	CancelledReverts []CancelledRevert `json:"CancelledReverts,omitempty"`
+	// CherryPicks lists commits counted only once because they copy an older
+	// commit in the same range.
+	CherryPicks []CherryPick `json:"CherryPicks,omitempty"`
+	// APICompatibility and APIIncompatible report the api-diff strategy's result:
+	// breaking, additive or none, and the removed or changed symbols.
+	APICompatibility string   `json:"APICompatibility,omitempty"`
+	APIIncompatible  []string `json:"APIIncompatible,omitempty"`
+	// Issues lists the issues referenced by the commits since the base version.
+	Issues   []Issue  `json:"Issues,omitempty"`
+	Warnings []string `json:"Warnings,omitempty"`
+}
+
+// Issue is an issue referenced by a commit, linked when a URL template is configured.
+type Issue struct {
+	ID   string `json:"ID"`
+	Text string `json:"Text"`
+	URL  string `json:"URL,omitempty"`
+}
+
+// CherryPick is a cherry-picked commit and the original it copies, both by SHA.
+type CherryPick struct {
+	CherryPick string `json:"CherryPick"`
+	Original   string `json:"Original"`
+}
+
+// CancelledRevert is a revert commit and the commit it undoes, both by SHA.
+type CancelledRevert struct {
+	Revert   string `json:"Revert"`
+	Reverted string `json:"Reverted"`
 }
 
-func buildVersionVariables(version *semver.Version, branchName string, commitsSinceTag int, config *gitversion.Config) VersionVariables {
-	finalVersion := *version
-	matchingBranchConfig := config.GetBranchConfig(branchName)
+func buildVersionVariables(result *gitversion.VersionContext) VersionVariables {
+	finalVersion := *result.NextVersion
+	branchName := result.CurrentBranchName
+	commitsSinceTag := result.CommitsSinceLastTag
+	matchingBranchConfig := result.Config.GetBranchConfig(branchName)
+	if result.SemverLabel != nil {
+		// A Semver-Label trailer replaces the branch label for this release only.
+		labelConfig := gitversion.BranchConfig{Tag: result.SemverLabel.Value}
+		if matchingBranchConfig != nil {
+			labelConfig.PreReleaseWeight = matchingBranchConfig.PreReleaseWeight
+		}
+		matchingBranchConfig = &labelConfig
+	}
 
 	if matchingBranchConfig != nil && commitsSinceTag > 0 {
 		tag := matchingBranchConfig.Tag
@@ -132,11 +370,50 @@ func buildVersionVariables(version *semver.Version, branchName string, commitsSi
 		}
 	}
 
-	return VersionVariables{
+	ecosystems := buildEcosystemVersions(finalVersion, result.Config.GetBranchConfig(branchName).IsRelease())
+	vars := VersionVariables{
 		Major:         fmt.Sprintf("%d", finalVersion.Major()),
 		Minor:         fmt.Sprintf("%d", finalVersion.Minor()),
 		Patch:         fmt.Sprintf("%d", finalVersion.Patch()),
 		PreReleaseTag: finalVersion.Prerelease(),
 		FullSemVer:    finalVersion.String(),
+		PEP440:        ecosystems.PEP440,
+		Debian:        ecosystems.Debian,
+		RPMVersion:    ecosystems.RPMVersion,
+		RPMRelease:    ecosystems.RPMRelease,
+		NuGetLegacy:   ecosystems.NuGetLegacy,
+		Maven:         ecosystems.Maven,
+		DockerTag:     ecosystems.DockerTag,
+		Warnings:      result.Warnings,
+	}
+	if result.HeadCommit != nil {
+		vars.Sha = result.HeadCommit.Hash.String()
+		vars.CommitDate = result.HeadCommit.Committer.When.Format(result.Config.CommitDateLayout())
+	}
+	if result.ReleaseAs != nil {
+		vars.ReleaseAsSource = result.ReleaseAs.Commit.Hash.String()
+	}
+	if result.SemverLabel != nil {
+		vars.SemverLabelSource = result.SemverLabel.Commit.Hash.String()
+	}
+	for _, pair := range result.CancelledReverts {
+		vars.CancelledReverts = append(vars.CancelledReverts, CancelledRevert{
+			Revert:   pair.Revert.Hash.String(),
+			Reverted: pair.Reverted.Hash.String(),
+		})
+	}
+	for _, pair := range result.CherryPicks {
+		vars.CherryPicks = append(vars.CherryPicks, CherryPick{
+			CherryPick: pair.CherryPick.Hash.String(),
+			Original:   pair.Original.Hash.String(),
+		})
+	}
+	for _, issue := range result.Issues {
+		vars.Issues = append(vars.Issues, Issue{ID: issue.ID, Text: issue.Text, URL: issue.URL})
+	}
+	if result.APIDiff != nil {
+		vars.APICompatibility = result.APIDiff.Compatibility
+		vars.APIIncompatible = result.APIDiff.Incompatible
 	}
+	return vars
 }
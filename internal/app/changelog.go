@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"gitversion-go/internal/fs"
+	"gitversion-go/internal/gitversion"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// ChangelogOptions holds the settings of the changelog command.
+type ChangelogOptions struct {
+	// From and To select a range between two released versions; by default the
+	// notes cover the base version up to HEAD.
+	From string
+	To   string
+	// Template is a file holding the Go template to render, overriding the
+	// changelog template from the configuration. A relative name is resolved
+	// against the repository.
+	Template string
+	// Project restricts the notes to one project from the projects config section.
+	Project string
+	// Update names a Keep a Changelog file, relative to the repository, to add the
+	// notes to instead of printing them.
+	Update string
+}
+
+const defaultChangelogTemplate = `## {{.Version}} ({{.Date.Format "2006-01-02"}})
+{{range .Sections}}
+### {{.Title}}
+
+{{range .Entries}}- {{if .Scope}}**{{.Scope}}:** {{end}}{{.Description}} ({{.ShortSHA}}){{template "issues" .Issues}}
+{{end}}{{end}}`
+
+// issuesTemplate renders issue references, linked where a URL is configured.
+const issuesTemplate = `{{define "issues"}}{{range $i, $issue := .}}{{if eq $i 0}}, {{else}} {{end}}{{if $issue.URL}}[{{$issue.Text}}]({{$issue.URL}}){{else}}{{$issue.Text}}{{end}}{{end}}{{end}}`
+
+// RunChangelog renders the release notes for the commits since the base version,
+// or between two versions, as classified by the version calculation.
+func RunChangelog(fsys fs.Filesystem, out io.Writer, path string, opts ChangelogOptions) error {
+	config, r, branchName, err := openRepository(fsys, path)
+	if err != nil {
+		return err
+	}
+	templateFile := opts.Template
+	if templateFile != "" {
+		templateFile = repositoryFile(path, templateFile)
+	}
+	tmpl, err := changelogTemplate(fsys, config, templateFile)
+	if err != nil {
+		return err
+	}
+
+	result, changelog, err := buildChangelog(r, config, branchName, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Update != "" {
+		return updateChangelogFile(fsys, out, r, config, result, changelog, path, opts.Update)
+	}
+	if err := tmpl.Execute(out, changelog); err != nil {
+		return fmt.Errorf("failed to render changelog: %w", err)
+	}
+	return nil
+}
+
+func buildChangelog(r *git.Repository, config *gitversion.Config, branchName string, opts ChangelogOptions) (*gitversion.VersionContext, *gitversion.Changelog, error) {
+	result, err := gitversion.CalculateRange(r, config, branchName, opts.Project, opts.From, opts.To)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to calculate changelog: %w", err)
+	}
+	version := result.NextVersion.String()
+	if opts.To == "" {
+		version = buildVersionVariables(result).FullSemVer
+	}
+	changelog, err := gitversion.BuildChangelog(result, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, changelog, nil
+}
+
+func changelogTemplate(fsys fs.Filesystem, config *gitversion.Config, templateFile string) (*template.Template, error) {
+	text := config.Changelog.Template
+	if templateFile != "" {
+		data, err := fsys.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read changelog template: %w", err)
+		}
+		text = string(data)
+	}
+	if text == "" {
+		text = defaultChangelogTemplate
+	}
+	tmpl, err := template.New("changelog").Parse(issuesTemplate)
+	if err == nil {
+		_, err = tmpl.Parse(text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid changelog template: %w", err)
+	}
+	return tmpl, nil
+}
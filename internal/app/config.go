@@ -0,0 +1,132 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gitversion-go/internal/fs"
+	"gitversion-go/internal/gitversion"
+
+	"github.com/go-git/go-git/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// RunConfigShow prints the configuration resolved for a branch, HEAD's branch
+// when branch is empty, as YAML. A comment after each setting tells whether it
+// comes from GitVersion.yml or the built-in defaults; the branches section only
+// holds the pattern matching the branch.
+func RunConfigShow(fsys fs.Filesystem, out io.Writer, path, branch string) error {
+	config, err := loadConfig(fsys, path)
+	if err != nil {
+		return err
+	}
+	if branch == "" {
+		r, err := git.PlainOpen(path)
+		if err != nil {
+			return fmt.Errorf("failed to open repository at %s: %w", path, err)
+		}
+		head, err := r.Head()
+		if err != nil {
+			return fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		branch = head.Name().Short()
+	}
+
+	doc, err := resolvedConfigNode(config, branch)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(out, "# Configuration for branch %s\n", branch); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to generate YAML output: %w", err)
+	}
+	return enc.Close()
+}
+
+// RunConfigValidate checks GitVersion.yml strictly, as calculate does before using
+// it, and reports every problem with its line and column.
+func RunConfigValidate(fsys fs.Filesystem, out io.Writer, path string) error {
+	exists, err := fsys.Exists(filepath.Join(path, "GitVersion.yml"))
+	if err != nil {
+		return fmt.Errorf("failed to read GitVersion.yml: %w", err)
+	}
+	if !exists {
+		_, err := fmt.Fprintln(out, "No GitVersion.yml found; the built-in defaults apply")
+		return err
+	}
+	if _, err := loadConfig(fsys, path); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, "GitVersion.yml is valid")
+	return err
+}
+
+// resolvedConfigNode encodes the configuration with the resolved branch settings,
+// commented with their sources. The settings of the branch have the source of its
+// pattern unless they are inherited.
+func resolvedConfigNode(config *gitversion.Config, branch string) (*yaml.Node, error) {
+	pattern, branchConfig, inherited := config.ResolveBranchConfig(branch)
+	resolved := *config
+	resolved.Branches = map[string]gitversion.BranchConfig{}
+	if branchConfig != nil {
+		resolved.Branches[pattern] = *branchConfig
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(&resolved); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+		if key.Value != "branches" {
+			setSource(key, value, config.SettingSource(key.Value))
+			continue
+		}
+		if branchConfig == nil {
+			value.LineComment = "no pattern matches " + branch
+			continue
+		}
+		for j := 0; j+1 < len(value.Content); j += 2 {
+			patternKey, fields := value.Content[j], value.Content[j+1]
+			source := config.BranchSource(pattern)
+			patternKey.LineComment = source
+			for k := 0; k+1 < len(fields.Content); k += 2 {
+				field, fieldValue := fields.Content[k], fields.Content[k+1]
+				if contains(inherited, field.Value) {
+					setSource(field, fieldValue, gitversion.SourceInherited)
+				} else {
+					setSource(field, fieldValue, source)
+				}
+			}
+		}
+	}
+	return &doc, nil
+}
+
+// setSource notes the source after a scalar value, or after the key of a
+// collection.
+func setSource(key, value *yaml.Node, source string) {
+	if source == "" {
+		return
+	}
+	if value.Kind == yaml.ScalarNode || value.Style&yaml.FlowStyle != 0 {
+		value.LineComment = source
+	} else {
+		key.LineComment = source
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
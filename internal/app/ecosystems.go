@@ -0,0 +1,159 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ecosystemVersions are the calculated version in the formats of package
+// ecosystems that reject or misorder semver pre-release strings.
+type ecosystemVersions struct {
+	PEP440      string
+	Debian      string
+	RPMVersion  string
+	RPMRelease  string
+	NuGetLegacy string
+	Maven       string
+	DockerTag   string
+}
+
+// prereleaseParts splits a pre-release such as "beta.1.3" into its label and
+// numeric identifiers: "beta" and [1 3]. Any character other than a letter or a
+// digit separates identifiers, so "release-1.2.1" gives "release" and [1 2 1].
+func prereleaseParts(prerelease string) (label string, numbers []uint64) {
+	var labels []string
+	for _, id := range nonAlphanumeric.Split(prerelease, -1) {
+		if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+			numbers = append(numbers, n)
+		} else if id != "" {
+			labels = append(labels, id)
+		}
+	}
+	return strings.Join(labels, "."), numbers
+}
+
+// pep440PreLabels maps pre-release labels to the PEP 440 pre-release segments.
+var pep440PreLabels = map[string]string{
+	"a": "a", "alpha": "a",
+	"b": "b", "beta": "b",
+	"c": "rc", "rc": "rc", "pre": "rc", "preview": "rc",
+}
+
+var (
+	nonAlphanumeric = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	nonDebianChars  = regexp.MustCompile(`[^A-Za-z0-9.+~]+`)
+	nonDockerChars  = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+func buildEcosystemVersions(v semver.Version, releaseBranch bool) ecosystemVersions {
+	core := fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch())
+	prerelease := v.Prerelease()
+	versions := ecosystemVersions{
+		PEP440:      core,
+		Debian:      core,
+		RPMVersion:  core,
+		RPMRelease:  "1",
+		NuGetLegacy: core,
+		Maven:       core,
+		DockerTag:   dockerTag(v.String()),
+	}
+	// Maven snapshots are the builds of non-release branches.
+	if !releaseBranch {
+		versions.Maven = core + "-SNAPSHOT"
+	} else if prerelease != "" {
+		versions.Maven = v.String()
+	}
+	if prerelease == "" {
+		return versions
+	}
+
+	label, numbers := prereleaseParts(prerelease)
+
+	// PEP 440: a known label becomes a pre-release segment ("b3"), and the further
+	// numbers a development release (".dev4"); anything else is a development
+	// release. PEP 440 only has one number per segment, so several numbers are
+	// joined with fixed-width padding ("beta.1.2.3" becomes "b1.dev20003").
+	if segment, ok := pep440PreLabels[strings.ToLower(label)]; ok {
+		n, rest := uint64(0), []uint64(nil)
+		if len(numbers) > 0 {
+			n, rest = numbers[0], numbers[1:]
+		}
+		versions.PEP440 = fmt.Sprintf("%s%s%d", core, segment, n)
+		if len(rest) > 0 {
+			dev, ok := joinNumbers(rest, false)
+			versions.PEP440 = ifEncoded(versions.PEP440+".dev"+dev, ok)
+		}
+	} else {
+		dev, ok := joinNumbers(numbers, false)
+		if dev == "" {
+			dev = "0"
+		}
+		versions.PEP440 = ifEncoded(core+".dev"+dev, ok)
+	}
+
+	// Debian and RPM sort "~" and a "0." release before the final release.
+	versions.Debian = core + "~" + nonDebianChars.ReplaceAllString(prerelease, ".")
+	versions.RPMRelease = "0." + nonAlphanumeric.ReplaceAllString(prerelease, ".")
+
+	// NuGet legacy (SemVer 1.0) allows a single alphanumeric identifier of at most
+	// 20 characters, compared as a string, so every number is zero-padded.
+	legacy := nonAlphanumeric.ReplaceAllString(label, "")
+	suffix, ok := joinNumbers(numbers, true)
+	if len(suffix) > 20 {
+		ok = false
+	}
+	if len(legacy)+len(suffix) > 20 {
+		legacy = legacy[:max(0, 20-len(suffix))]
+	}
+	if legacy+suffix != "" {
+		versions.NuGetLegacy = ifEncoded(core+"-"+legacy+suffix, ok)
+	}
+	return versions
+}
+
+// numberWidth is the width numbers are padded to when several of them are
+// joined into one; larger numbers cannot be encoded without losing their order.
+const numberWidth = 4
+
+// joinNumbers joins numbers into one so that they keep their order, padding each
+// to numberWidth digits; with padFirst false, the first one is not padded, which
+// gives the same order when the result is compared as a number. It reports false
+// when a padded number does not fit.
+func joinNumbers(numbers []uint64, padFirst bool) (string, bool) {
+	var b strings.Builder
+	for i, n := range numbers {
+		if i == 0 && !padFirst {
+			b.WriteString(strconv.FormatUint(n, 10))
+			continue
+		}
+		s := strconv.FormatUint(n, 10)
+		if len(s) > numberWidth {
+			return "", false
+		}
+		b.WriteString(strings.Repeat("0", numberWidth-len(s)) + s)
+	}
+	return b.String(), true
+}
+
+// ifEncoded returns version, or "" when the pre-release could not be encoded
+// without changing the order of versions.
+func ifEncoded(version string, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return version
+}
+
+// dockerTag makes a version usable as a Docker image tag: at most 128 characters
+// from [A-Za-z0-9_.-], not starting with "." or "-".
+func dockerTag(s string) string {
+	tag := strings.TrimLeft(nonDockerChars.ReplaceAllString(s, "-"), ".-")
+	if len(tag) > 128 {
+		tag = tag[:128]
+	}
+	return tag
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gitversion-go/internal/gitversion"
+)
+
+// writeTrace prints the decisions of a calculation as a tree.
+func writeTrace(out io.Writer, trace *gitversion.Trace) error {
+	var b strings.Builder
+	if trace.BranchPattern != "" {
+		fmt.Fprintf(&b, "Branch: %s (matched branches config %q)\n", trace.Branch, trace.BranchPattern)
+	} else {
+		fmt.Fprintf(&b, "Branch: %s (no branches config matched)\n", trace.Branch)
+	}
+
+	b.WriteString("Strategies\n")
+	for i, strategy := range trace.Strategies {
+		fmt.Fprintf(&b, "%s%s: %s\n", treeBranch(i, len(trace.Strategies)), strategy.Name, strategy.Outcome)
+	}
+
+	if len(trace.Tags) > 0 {
+		b.WriteString("Tags\n")
+		for i, tag := range trace.Tags {
+			status := "candidate"
+			if tag.Rejected != "" {
+				status = "rejected: " + tag.Rejected
+			} else if tag.Selected {
+				status = "selected"
+			}
+			fmt.Fprintf(&b, "%s%s (%s) %s\n", treeBranch(i, len(trace.Tags)), tag.Name, tag.Source, status)
+		}
+	}
+
+	if trace.BaseVersion != "" {
+		fmt.Fprintf(&b, "Base version: %s at %s\n", trace.BaseVersion, shortHash(trace.BaseCommit))
+	} else {
+		b.WriteString("Base version: none\n")
+	}
+
+	if len(trace.Commits) > 0 {
+		b.WriteString("Commits\n")
+		for i, commit := range trace.Commits {
+			outcome := fmt.Sprintf("%s (%s)", commit.Bump, commit.Rule)
+			if commit.Skipped != "" {
+				outcome = "skipped: " + commit.Skipped
+			}
+			fmt.Fprintf(&b, "%s%s %s -> %s\n", treeBranch(i, len(trace.Commits)), shortHash(commit.SHA), commit.Message, outcome)
+		}
+	}
+
+	if trace.BumpRule != "" {
+		if trace.Bump != "" {
+			fmt.Fprintf(&b, "Bump: %s (%s)\n", trace.Bump, trace.BumpRule)
+		} else {
+			fmt.Fprintf(&b, "Bump: %s\n", trace.BumpRule)
+		}
+	}
+	fmt.Fprintf(&b, "Next version: %s\n", trace.NextVersion)
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func treeBranch(i, n int) string {
+	if i == n-1 {
+		return "└── "
+	}
+	return "├── "
+}
+
+func shortHash(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"gitversion-go/internal/fs"
+)
+
+// GenerateOptions holds the settings of the generate command.
+type GenerateOptions struct {
+	// Lang is the kind of file to write: go, json or VERSION.
+	Lang string
+	// Out is the file to write; it defaults to a name per language.
+	Out string
+	// Package is the Go package name; it defaults to the name of Out's directory.
+	Package string
+	// Project restricts the calculation to one project from the projects config section.
+	Project string
+}
+
+// generateTemplates are the version source file templates by language.
+var generateTemplates = map[string]*template.Template{
+	"go": template.Must(template.New("go").Parse(`// Code generated by gitversion-go generate; DO NOT EDIT.
+
+package {{.Package}}
+
+// Version information of this build.
+const (
+	Major      = {{.Major}}
+	Minor      = {{.Minor}}
+	Patch      = {{.Patch}}
+	FullSemVer = {{printf "%q" .FullSemVer}}
+	Sha        = {{printf "%q" .Sha}}
+	CommitDate = {{printf "%q" .CommitDate}}
+)
+`)),
+	"json": template.Must(template.New("json").Funcs(template.FuncMap{"json": jsonString}).Parse(`{
+  "Major": {{.Major}},
+  "Minor": {{.Minor}},
+  "Patch": {{.Patch}},
+  "FullSemVer": {{json .FullSemVer}},
+  "Sha": {{json .Sha}},
+  "CommitDate": {{json .CommitDate}}
+}
+`)),
+	"VERSION": template.Must(template.New("VERSION").Parse("{{.FullSemVer}}\n")),
+}
+
+// jsonString encodes s as a JSON string; Go's %q escapes differ from JSON's.
+func jsonString(s string) (string, error) {
+	data, err := json.Marshal(s)
+	return string(data), err
+}
+
+var defaultGenerateOut = map[string]string{
+	"go":      "version_gen.go",
+	"json":    "version.json",
+	"VERSION": "VERSION",
+}
+
+// RunGenerate calculates the version of the repository at path and writes it to a
+// source file; a relative Out is resolved against path. The file is left
+// untouched when its content would not change.
+func RunGenerate(fsys fs.Filesystem, out io.Writer, path string, opts GenerateOptions) error {
+	tmpl, ok := generateTemplates[opts.Lang]
+	if !ok {
+		return fmt.Errorf("unknown language: %s (expected go, json or VERSION)", opts.Lang)
+	}
+	outFile := opts.Out
+	if outFile == "" {
+		outFile = defaultGenerateOut[opts.Lang]
+	}
+
+	config, r, branchName, err := openRepository(fsys, path)
+	if err != nil {
+		return err
+	}
+	result, err := calculateProject(r, config, branchName, opts.Project)
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		VersionVariables
+		Package string
+	}{buildVersionVariables(result), opts.Package}
+	if data.Package == "" {
+		data.Package = goPackageName(outFile)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", outFile, err)
+	}
+
+	file := repositoryFile(path, outFile)
+	existing, err := fsys.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", outFile, err)
+	}
+	if err == nil && bytes.Equal(existing, buf.Bytes()) {
+		_, err = fmt.Fprintf(out, "%s is up to date\n", outFile)
+		return err
+	}
+	if err := fsys.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory of %s: %w", outFile, err)
+	}
+	if err := fsys.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outFile, err)
+	}
+	_, err = fmt.Fprintf(out, "Wrote %s\n", outFile)
+	return err
+}
+
+var nonIdentifierChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// goPackageName derives a package name from the directory of the output file,
+// e.g. "version" for internal/version/version_gen.go, and "main" at the top level.
+func goPackageName(outFile string) string {
+	dir := filepath.Base(filepath.Dir(filepath.Clean(outFile)))
+	if dir == "." || dir == string(filepath.Separator) {
+		return "main"
+	}
+	name := nonIdentifierChars.ReplaceAllString(strings.ToLower(dir), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
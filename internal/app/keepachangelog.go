@@ -0,0 +1,252 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"gitversion-go/internal/fs"
+	"gitversion-go/internal/gitversion"
+
+	"github.com/go-git/go-git/v5"
+)
+
+const keepAChangelogHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+
+## [Unreleased]
+`
+
+var keepAChangelogSection = template.Must(template.Must(template.New("section").Parse(issuesTemplate)).Parse(`## [{{.Version}}] - {{.Date.Format "2006-01-02"}}
+{{range .Sections}}
+### {{.Title}}
+
+{{range .Entries}}- {{if .Scope}}**{{.Scope}}:** {{end}}{{.Description}} ({{.ShortSHA}}){{template "issues" .Issues}}
+{{end}}{{end}}`))
+
+var (
+	unreleasedHeading = regexp.MustCompile(`(?i)^## \[unreleased\]`)
+	unreleasedLink    = regexp.MustCompile(`(?i)^\[unreleased\]:`)
+	linkDefinition    = regexp.MustCompile(`^\[[^\]]+\]:\s`)
+)
+
+// changelogLinks are the link reference definitions for a new release.
+type changelogLinks struct {
+	unreleased string // compares the new tag with HEAD
+	version    string // compares the previous tag with the new tag
+}
+
+// updateChangelogFile adds the release notes to a Keep a Changelog file, creating
+// it if needed. A relative fileName is resolved against the repository at path.
+// Nothing is written when the file already has the version.
+func updateChangelogFile(fsys fs.Filesystem, out io.Writer, r *git.Repository, config *gitversion.Config, result *gitversion.VersionContext, changelog *gitversion.Changelog, path, fileName string) error {
+	write, err := prepareChangelogUpdate(fsys, out, r, config, result, changelog, path, fileName)
+	if err != nil {
+		return err
+	}
+	return write()
+}
+
+// prepareChangelogUpdate renders the update of updateChangelogFile and returns
+// the function that writes it.
+func prepareChangelogUpdate(fsys fs.Filesystem, out io.Writer, r *git.Repository, config *gitversion.Config, result *gitversion.VersionContext, changelog *gitversion.Changelog, path, fileName string) (func() error, error) {
+	file := repositoryFile(path, fileName)
+	existing, err := fsys.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", fileName, err)
+	}
+	if len(existing) == 0 {
+		existing = []byte(keepAChangelogHeader)
+	}
+
+	var section bytes.Buffer
+	if err := keepAChangelogSection.Execute(&section, changelog); err != nil {
+		return nil, fmt.Errorf("failed to render changelog: %w", err)
+	}
+	links, err := releaseLinks(r, config, result, changelog.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, changed := insertRelease(string(existing), changelog.Version, section.String(), links)
+	return func() error {
+		if !changed {
+			_, err := fmt.Fprintf(out, "%s already has %s\n", fileName, changelog.Version)
+			return err
+		}
+		if err := fsys.WriteFile(file, []byte(updated), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", fileName, err)
+		}
+		_, err := fmt.Fprintf(out, "Updated %s with %s\n", fileName, changelog.Version)
+		return err
+	}, nil
+}
+
+// insertRelease puts the section of a new version below the Unreleased section and
+// updates the compare links at the bottom. Older sections are left as they are.
+func insertRelease(content, version, section string, links changelogLinks) (string, bool) {
+	versionHeading := regexp.MustCompile(`^## \[` + regexp.QuoteMeta(version) + `\]`)
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	for _, line := range lines {
+		if versionHeading.MatchString(line) {
+			return content, false
+		}
+	}
+
+	// The link reference definitions at the bottom of the file.
+	linksStart := len(lines)
+	for linksStart > 0 && (linkDefinition.MatchString(lines[linksStart-1]) || strings.TrimSpace(lines[linksStart-1]) == "") {
+		linksStart--
+	}
+	body, linkLines := lines[:linksStart], lines[linksStart:]
+
+	unreleased := -1
+	for i, line := range body {
+		if unreleasedHeading.MatchString(line) {
+			unreleased = i
+			break
+		}
+	}
+	if unreleased < 0 {
+		insertAt := len(body)
+		for i, line := range body {
+			if strings.HasPrefix(line, "## ") {
+				insertAt = i
+				break
+			}
+		}
+		body = insertLines(body, insertAt, "## [Unreleased]", "")
+		unreleased = insertAt
+	}
+
+	insertAt := len(body)
+	for i := unreleased + 1; i < len(body); i++ {
+		if strings.HasPrefix(body[i], "## ") {
+			insertAt = i
+			break
+		}
+	}
+	newLines := strings.Split(strings.TrimRight(section, "\n"), "\n")
+	newLines = append(newLines, "")
+	if insertAt > 0 && strings.TrimSpace(body[insertAt-1]) != "" {
+		newLines = append([]string{""}, newLines...)
+	}
+	body = insertLines(body, insertAt, newLines...)
+	for len(body) > 0 && strings.TrimSpace(body[len(body)-1]) == "" {
+		body = body[:len(body)-1]
+	}
+
+	linkLines = updateLinks(linkLines, version, links)
+	result := strings.Join(body, "\n") + "\n"
+	if len(linkLines) > 0 {
+		result += "\n" + strings.Join(linkLines, "\n") + "\n"
+	}
+	return result, true
+}
+
+func updateLinks(lines []string, version string, links changelogLinks) []string {
+	var kept []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			kept = append(kept, line)
+		}
+	}
+	if links.unreleased == "" {
+		return kept
+	}
+
+	unreleased := -1
+	for i, line := range kept {
+		if unreleasedLink.MatchString(line) {
+			unreleased = i
+			kept[i] = "[unreleased]: " + links.unreleased
+			break
+		}
+	}
+	if unreleased < 0 {
+		kept = insertLines(kept, 0, "[unreleased]: "+links.unreleased)
+		unreleased = 0
+	}
+	if links.version != "" {
+		kept = insertLines(kept, unreleased+1, fmt.Sprintf("[%s]: %s", version, links.version))
+	}
+	return kept
+}
+
+func insertLines(lines []string, at int, inserted ...string) []string {
+	result := make([]string, 0, len(lines)+len(inserted))
+	result = append(result, lines[:at]...)
+	result = append(result, inserted...)
+	return append(result, lines[at:]...)
+}
+
+// releaseLinks builds the compare links for a new version. The new tag is named
+// like the previous one, e.g. v1.3.0 after v1.2.0, or v1.3.0 for the first release.
+func releaseLinks(r *git.Repository, config *gitversion.Config, result *gitversion.VersionContext, version string) (changelogLinks, error) {
+	compareURL := config.Changelog.CompareURL
+	if compareURL == "" {
+		base, err := originWebURL(r)
+		if err != nil || base == "" {
+			return changelogLinks{}, err
+		}
+		compareURL = base + "/compare/{from}...{to}"
+	}
+
+	previousTag, err := result.BaseVersionTag()
+	if err != nil {
+		return changelogLinks{}, err
+	}
+	newTag, err := result.NewVersionTag(version)
+	if err != nil {
+		return changelogLinks{}, err
+	}
+
+	compare := func(from, to string) string {
+		return strings.NewReplacer("{from}", from, "{to}", to).Replace(compareURL)
+	}
+	links := changelogLinks{unreleased: compare(newTag, "HEAD")}
+	if previousTag != "" {
+		links.version = compare(previousTag, newTag)
+	}
+	return links, nil
+}
+
+var scpLikeURL = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// originWebURL derives the web URL of the origin remote, e.g. https://github.com/o/r
+// for git@github.com:o/r.git. It returns "" when there is no origin remote.
+func originWebURL(r *git.Repository) (string, error) {
+	remote, err := r.Remote("origin")
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", nil
+	}
+
+	raw := urls[0]
+	var host, repoPath string
+	if u, err := url.Parse(raw); err == nil && u.Scheme != "" && u.Host != "" {
+		host, repoPath = u.Hostname(), u.Path
+	} else if m := scpLikeURL.FindStringSubmatch(raw); m != nil {
+		host, repoPath = m[1], m[2]
+	} else {
+		return "", nil
+	}
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	return "https://" + host + "/" + repoPath, nil
+}
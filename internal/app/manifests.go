@@ -0,0 +1,244 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifestEditors set the version in a manifest, recognised by its file name.
+// Each one edits only the bytes of the version value, so comments, indentation
+// and key order are kept.
+var manifestEditors = map[string]versionEditor{
+	"package.json":   setJSONVersion,
+	"Chart.yaml":     setChartVersion,
+	"Cargo.toml":     setCargoVersion,
+	"pyproject.toml": setPyprojectVersion,
+	"pom.xml":        setPOMVersion,
+}
+
+// versionEditor returns data with the version set.
+type versionEditor func(data []byte, version string) ([]byte, error)
+
+// textEdit replaces data[start:end] with text.
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+func applyEdits(data []byte, edits []textEdit) []byte {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	result := append([]byte(nil), data...)
+	for _, e := range edits {
+		result = append(result[:e.start], append([]byte(e.text), result[e.end:]...)...)
+	}
+	return result
+}
+
+// setJSONVersion sets the top-level "version" string of a package.json.
+func setJSONVersion(data []byte, version string) ([]byte, error) {
+	type frame struct{ object, key bool }
+	var stack []frame
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New(`no top-level "version" found`)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+
+		if len(stack) > 0 && stack[len(stack)-1].key {
+			stack[len(stack)-1].key = false
+			if len(stack) == 1 && tok == "version" {
+				start := int(dec.InputOffset())
+				value, err := dec.Token()
+				if err != nil {
+					return nil, fmt.Errorf("invalid JSON: %w", err)
+				}
+				if _, ok := value.(string); !ok {
+					return nil, errors.New(`"version" is not a string`)
+				}
+				end := int(dec.InputOffset())
+				start += bytes.IndexByte(data[start:end], '"')
+				quoted, err := json.Marshal(version)
+				if err != nil {
+					return nil, err
+				}
+				return applyEdits(data, []textEdit{{start, end, string(quoted)}}), nil
+			}
+			continue
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, frame{object: true, key: true})
+			continue
+		case json.Delim('['):
+			stack = append(stack, frame{})
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) > 0 && stack[len(stack)-1].object {
+			stack[len(stack)-1].key = true
+		}
+	}
+}
+
+// setChartVersion sets the top-level version and appVersion of a Helm Chart.yaml,
+// keeping the quoting style of each value.
+func setChartVersion(data []byte, version string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("not a YAML mapping")
+	}
+
+	lineStarts := []int{0}
+	for i, b := range data {
+		if b == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	var edits []textEdit
+	mapping := doc.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if (key.Value != "version" && key.Value != "appVersion") || value.Kind != yaml.ScalarNode {
+			continue
+		}
+		start := lineStarts[value.Line-1] + value.Column - 1
+		switch {
+		case value.Style&yaml.DoubleQuotedStyle != 0:
+			end := start + 1 + bytes.IndexByte(data[start+1:], '"') + 1
+			edits = append(edits, textEdit{start, end, `"` + version + `"`})
+		case value.Style&yaml.SingleQuotedStyle != 0:
+			end := start + 1 + bytes.IndexByte(data[start+1:], '\'') + 1
+			edits = append(edits, textEdit{start, end, "'" + version + "'"})
+		default:
+			edits = append(edits, textEdit{start, start + len(value.Value), version})
+		}
+	}
+	if len(edits) == 0 {
+		return nil, errors.New("no top-level version or appVersion found")
+	}
+	return applyEdits(data, edits), nil
+}
+
+var (
+	tomlTableHeader = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`)
+	tomlArrayHeader = regexp.MustCompile(`^\s*\[\[\s*([^\[\]]+?)\s*\]\]\s*(#.*)?$`)
+	tomlVersionKey  = regexp.MustCompile(`^\s*version\s*=\s*["']([^"']*)["']`)
+)
+
+func setCargoVersion(data []byte, version string) ([]byte, error) {
+	return setTOMLVersion(data, version, "package", "workspace.package")
+}
+
+func setPyprojectVersion(data []byte, version string) ([]byte, error) {
+	return setTOMLVersion(data, version, "project", "tool.poetry")
+}
+
+// setTOMLVersion sets the version key of the first of the given tables present.
+func setTOMLVersion(data []byte, version string, tables ...string) ([]byte, error) {
+	found := make(map[string]textEdit)
+	table := ""
+	offset := 0
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if m := tomlTableHeader.FindStringSubmatch(line); m != nil {
+			table = m[1]
+		} else if m := tomlArrayHeader.FindStringSubmatch(line); m != nil {
+			// Keys of an array of tables, such as [[bin]], belong to none of the tables.
+			table = "[[" + m[1] + "]]"
+		} else if m := tomlVersionKey.FindStringSubmatchIndex(line); m != nil {
+			if _, ok := found[table]; !ok {
+				found[table] = textEdit{offset + m[2], offset + m[3], version}
+			}
+		}
+		offset += len(line)
+	}
+	for _, t := range tables {
+		if edit, ok := found[t]; ok {
+			return applyEdits(data, []textEdit{edit}), nil
+		}
+	}
+	return nil, fmt.Errorf("no version found in [%s]", strings.Join(tables, "] or ["))
+}
+
+// setPOMVersion sets the project's own <version>, leaving the versions of the
+// parent, dependencies and plugins alone.
+func setPOMVersion(data []byte, version string) ([]byte, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var path []string
+	for {
+		offset := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no <project><version> found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			if len(path) == 2 && path[0] == "project" && t.Name.Local == "version" {
+				var escaped bytes.Buffer
+				if err := xml.EscapeText(&escaped, []byte(version)); err != nil {
+					return nil, err
+				}
+				start := int(dec.InputOffset())
+				if bytes.HasSuffix(data[:start], []byte("/>")) {
+					// Replace a self-closing <version/> by an element with content.
+					return applyEdits(data, []textEdit{{offset, start, "<version>" + escaped.String() + "</version>"}}), nil
+				}
+				end := start
+				if next, err := dec.Token(); err != nil {
+					return nil, fmt.Errorf("invalid XML: %w", err)
+				} else if _, ok := next.(xml.CharData); ok {
+					end = int(dec.InputOffset())
+				}
+				return applyEdits(data, []textEdit{{start, end, escaped.String()}}), nil
+			}
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		}
+	}
+}
+
+// regexEditor returns an editor that replaces the first capture group of every
+// match of pattern with the version.
+func regexEditor(pattern string) (versionEditor, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if re.NumSubexp() < 1 {
+		return nil, fmt.Errorf("pattern %q has no capture group for the version", pattern)
+	}
+	return func(data []byte, version string) ([]byte, error) {
+		var edits []textEdit
+		for _, m := range re.FindAllSubmatchIndex(data, -1) {
+			if m[2] >= 0 {
+				edits = append(edits, textEdit{m[2], m[3], version})
+			}
+		}
+		if len(edits) == 0 {
+			return nil, fmt.Errorf("pattern %q matches nothing", pattern)
+		}
+		return applyEdits(data, edits), nil
+	}, nil
+}
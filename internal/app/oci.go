@@ -0,0 +1,55 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gitversion-go/internal/gitversion"
+)
+
+// OCIOutput is the oci output format: the image tags for the calculated version
+// and the OCI annotations to set on the image.
+type OCIOutput struct {
+	Tags        []string          `json:"Tags"`
+	Annotations map[string]string `json:"Annotations"`
+}
+
+// buildOCIOutput returns the tags 1.2.3, 1.2, 1 and latest for a stable version
+// on a release branch, and the version plus <branch>-<sha> otherwise, so that
+// floating tags only ever move to releases.
+func buildOCIOutput(result *gitversion.VersionContext, vars VersionVariables) OCIOutput {
+	var tags []string
+	if vars.PreReleaseTag == "" && result.Config.GetBranchConfig(result.CurrentBranchName).IsRelease() {
+		tags = []string{
+			vars.DockerTag,
+			fmt.Sprintf("%s.%s", vars.Major, vars.Minor),
+			vars.Major,
+			"latest",
+		}
+	} else {
+		tags = []string{vars.DockerTag}
+		if len(vars.Sha) >= 7 {
+			tags = append(tags, dockerTag(result.CurrentBranchName+"-"+vars.Sha[:7]))
+		}
+	}
+
+	annotations := map[string]string{
+		"org.opencontainers.image.version": vars.FullSemVer,
+	}
+	if result.HeadCommit != nil {
+		annotations["org.opencontainers.image.revision"] = vars.Sha
+		annotations["org.opencontainers.image.created"] = result.HeadCommit.Committer.When.UTC().Format(time.RFC3339)
+	}
+	return OCIOutput{Tags: tags, Annotations: annotations}
+}
+
+func writeOCIOutput(out io.Writer, result *gitversion.VersionContext, vars VersionVariables) error {
+	data, err := json.Marshal(buildOCIOutput(result, vars))
+	if err != nil {
+		return fmt.Errorf("failed to generate OCI output: %w", err)
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
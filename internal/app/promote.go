@@ -0,0 +1,76 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"gitversion-go/internal/fs"
+	"gitversion-go/internal/gitversion"
+)
+
+// PromoteOptions holds the settings of the promote command. The embedded tag
+// options apply to the final version's tag.
+type PromoteOptions struct {
+	TagOptions
+	// Changelog prints the notes of the final version, gathering the entries of
+	// all its pre-releases.
+	Changelog bool
+	// Update names a Keep a Changelog file, relative to the repository, to add
+	// those notes to.
+	Update string
+}
+
+// RunPromote tags the commit of a pre-release with its final version, e.g.
+// 1.4.0 for 1.4.0-rc.3.
+func RunPromote(fsys fs.Filesystem, out io.Writer, path, version string, opts PromoteOptions) error {
+	config, r, branchName, err := openRepository(fsys, path)
+	if err != nil {
+		return err
+	}
+	promotion, err := gitversion.CalculatePromotion(r, config, branchName, opts.Project, version)
+	if err != nil {
+		return fmt.Errorf("failed to promote %s: %w", version, err)
+	}
+	result := promotion.Context
+	vars := buildVersionVariables(result)
+	for _, warning := range vars.Warnings {
+		if _, err := fmt.Fprintf(out, "Warning: %s\n", warning); err != nil {
+			return err
+		}
+	}
+
+	// The release notes are prepared before tagging, so that a failure leaves no tag behind.
+	var notes bytes.Buffer
+	writeChangelog := func() error { return nil }
+	if opts.Changelog || opts.Update != "" {
+		changelog, err := gitversion.BuildChangelog(result, vars.FullSemVer)
+		if err != nil {
+			return err
+		}
+		if opts.Update != "" {
+			if writeChangelog, err = prepareChangelogUpdate(fsys, out, r, result.Config, result, changelog, path, opts.Update); err != nil {
+				return err
+			}
+		} else {
+			tmpl, err := changelogTemplate(fsys, result.Config, "")
+			if err != nil {
+				return err
+			}
+			if err := tmpl.Execute(&notes, changelog); err != nil {
+				return fmt.Errorf("failed to render changelog: %w", err)
+			}
+		}
+	}
+
+	err = createTag(fsys, out, r, config, path, opts.TagOptions, promotion.FinalTag, result.HeadCommit.Hash, func() (string, error) {
+		return tagMessage(fsys, result, vars, promotion.FinalTag)
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(notes.Bytes()); err != nil {
+		return err
+	}
+	return writeChangelog()
+}
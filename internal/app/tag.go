@@ -0,0 +1,267 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"gitversion-go/internal/fs"
+	"gitversion-go/internal/gitversion"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// TagOptions holds the settings of the tag command.
+type TagOptions struct {
+	// Annotated creates an annotated tag even when tag.annotated is not configured.
+	Annotated bool
+	// Remote names a remote to push the new tag to.
+	Remote string
+	// Project tags the version of one project from the projects config section.
+	Project string
+	// SigningKey is the key to sign the tag with, overriding tag.signing-key: a
+	// file relative to the repository, or for the gpg format a key ID. Signed tags
+	// are always annotated.
+	SigningKey string
+	// SigningFormat overrides tag.signing-format: openpgp, gpg or ssh.
+	SigningFormat string
+	// Passphrase decrypts an OpenPGP signing key file if it is encrypted.
+	Passphrase string
+}
+
+const defaultTagMessage = "Release {{.Tag}}"
+
+// tagMessageData is what the tag message template is rendered with.
+type tagMessageData struct {
+	VersionVariables
+	Tag          string
+	ReleaseNotes string
+}
+
+// RunTag calculates the version and tags HEAD with it. It refuses to tag a dirty
+// worktree, a HEAD that already carries a version tag, or a version that has
+// already been tagged elsewhere.
+func RunTag(fsys fs.Filesystem, out io.Writer, path string, opts TagOptions) error {
+	config, r, branchName, err := openRepository(fsys, path)
+	if err != nil {
+		return err
+	}
+	if err := checkCleanWorktree(r); err != nil {
+		return err
+	}
+
+	result, err := calculateProject(r, config, branchName, opts.Project)
+	if err != nil {
+		return err
+	}
+	vars := buildVersionVariables(result)
+	for _, warning := range vars.Warnings {
+		if _, err := fmt.Fprintf(out, "Warning: %s\n", warning); err != nil {
+			return err
+		}
+	}
+	name, err := newTagName(result, vars)
+	if err != nil {
+		return err
+	}
+
+	existing, err := result.VersionTagsOn(result.HeadCommit.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to read tags: %w", err)
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("HEAD is already tagged as %s", strings.Join(existing, ", "))
+	}
+	version, err := semver.NewVersion(vars.FullSemVer)
+	if err != nil {
+		return fmt.Errorf("invalid version %s: %w", vars.FullSemVer, err)
+	}
+	if tagged, err := result.FindVersionTag(version); err != nil {
+		return fmt.Errorf("failed to read tags: %w", err)
+	} else if tagged != "" {
+		return fmt.Errorf("version %s already exists as tag %s", vars.FullSemVer, tagged)
+	}
+
+	return createTag(fsys, out, r, config, path, opts, name, result.HeadCommit.Hash, func() (string, error) {
+		return tagMessage(fsys, result, vars, name)
+	})
+}
+
+// createTag creates the tag, annotated with the message when annotated or signed
+// tags are configured, and pushes it if a remote is given.
+func createTag(fsys fs.Filesystem, out io.Writer, r *git.Repository, config *gitversion.Config, path string, opts TagOptions, name string, hash plumbing.Hash, message func() (string, error)) error {
+	signer, err := newTagSigner(fsys, path, config, opts)
+	if err != nil {
+		return err
+	}
+	var tagOpts *git.CreateTagOptions
+	if opts.Annotated || config.Tag.Annotated || signer != nil {
+		text, err := message()
+		if err != nil {
+			return err
+		}
+		tagOpts = &git.CreateTagOptions{Message: text}
+	}
+	if signer != nil {
+		_, err = gitversion.CreateSignedTag(r, name, hash, tagOpts.Message, signer)
+	} else {
+		_, err = r.CreateTag(name, hash, tagOpts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", name, err)
+	}
+	if _, err := fmt.Fprintf(out, "Created tag %s\n", name); err != nil {
+		return err
+	}
+
+	if opts.Remote == "" {
+		return nil
+	}
+	refSpec := gitconfig.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", name, name))
+	err = r.Push(&git.PushOptions{RemoteName: opts.Remote, RefSpecs: []gitconfig.RefSpec{refSpec}})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to push tag %s to %s: %w", name, opts.Remote, err)
+	}
+	_, err = fmt.Fprintf(out, "Pushed tag %s to %s\n", name, opts.Remote)
+	return err
+}
+
+// newTagSigner returns the signer for the key from --sign-key or tag.signing-key
+// in the format from --sign-format or tag.signing-format, or nil to not sign. Key
+// files of either origin are relative to the repository.
+func newTagSigner(fsys fs.Filesystem, path string, config *gitversion.Config, opts TagOptions) (git.Signer, error) {
+	key := opts.SigningKey
+	if key == "" {
+		key = config.Tag.SigningKey
+	}
+	format := opts.SigningFormat
+	if format == "" {
+		format = config.Tag.SigningFormat
+	}
+	if key == "" {
+		return nil, nil
+	}
+
+	switch strings.ToLower(format) {
+	case "", gitversion.SigningFormatOpenPGP:
+		armored, err := fsys.ReadFile(repositoryFile(path, key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		entity, err := gitversion.ReadSigningKey(armored, opts.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return gitversion.NewOpenPGPSigner(entity), nil
+	case gitversion.SigningFormatGPG:
+		return gitversion.NewGPGSigner(key), nil
+	case gitversion.SigningFormatSSH:
+		file := repositoryFile(path, key)
+		if exists, err := fsys.Exists(file); err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		} else if !exists {
+			return nil, fmt.Errorf("failed to read signing key: %s does not exist", file)
+		}
+		return gitversion.NewSSHSigner(file), nil
+	default:
+		return nil, fmt.Errorf("unknown signing format %q (expected openpgp, gpg or ssh)", format)
+	}
+}
+
+// checkCleanWorktree fails when tracked files have uncommitted changes. Untracked
+// files are ignored, as they are by git describe --dirty.
+func checkCleanWorktree(r *git.Repository) error {
+	w, err := r.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree: %w", err)
+	}
+	status, err := w.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree status: %w", err)
+	}
+	for file, s := range status {
+		if s.Staging == git.Untracked && s.Worktree == git.Untracked {
+			continue
+		}
+		if s.Staging != git.Unmodified || s.Worktree != git.Unmodified {
+			return fmt.Errorf("worktree has uncommitted changes: %s", file)
+		}
+	}
+	return nil
+}
+
+// newTagName renders the tag.name template, or names the tag like the base
+// version's tag. The name has to match the tag prefix, or the tag would not be
+// picked up by later calculations.
+func newTagName(result *gitversion.VersionContext, vars VersionVariables) (string, error) {
+	var name string
+	if text := result.Config.Tag.Name; text != "" {
+		rendered, err := renderTemplate("tag name", text, vars)
+		if err != nil {
+			return "", err
+		}
+		name = strings.TrimSpace(rendered)
+	} else {
+		var err error
+		if name, err = result.NewVersionTag(vars.FullSemVer); err != nil {
+			return "", fmt.Errorf("failed to name tag: %w", err)
+		}
+	}
+
+	if v, ok := result.ParseVersionTag(name); !ok || v.String() != vars.FullSemVer {
+		return "", fmt.Errorf("tag %s does not match tag-prefix as version %s", name, vars.FullSemVer)
+	}
+	return name, nil
+}
+
+// tagMessage renders the tag.message template. Release notes are only built when
+// the template uses them.
+func tagMessage(fsys fs.Filesystem, result *gitversion.VersionContext, vars VersionVariables, name string) (string, error) {
+	text := result.Config.Tag.Message
+	if text == "" {
+		text = defaultTagMessage
+	}
+	data := tagMessageData{VersionVariables: vars, Tag: name}
+	if strings.Contains(text, ".ReleaseNotes") {
+		notes, err := releaseNotes(fsys, result, vars.FullSemVer)
+		if err != nil {
+			return "", err
+		}
+		data.ReleaseNotes = notes
+	}
+	return renderTemplate("tag message", text, data)
+}
+
+func releaseNotes(fsys fs.Filesystem, result *gitversion.VersionContext, version string) (string, error) {
+	tmpl, err := changelogTemplate(fsys, result.Config, "")
+	if err != nil {
+		return "", err
+	}
+	changelog, err := gitversion.BuildChangelog(result, version)
+	if err != nil {
+		return "", err
+	}
+	var notes bytes.Buffer
+	if err := tmpl.Execute(&notes, changelog); err != nil {
+		return "", fmt.Errorf("failed to render changelog: %w", err)
+	}
+	return notes.String(), nil
+}
+
+func renderTemplate(what, text string, data any) (string, error) {
+	tmpl, err := template.New(what).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", what, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", what, err)
+	}
+	return buf.String(), nil
+}
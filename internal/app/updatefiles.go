@@ -0,0 +1,186 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gitversion-go/internal/fs"
+	"gitversion-go/internal/gitversion"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// UpdateFilesOptions holds the settings of the update-files command.
+type UpdateFilesOptions struct {
+	// DryRun prints a diff of the changes instead of writing them.
+	DryRun bool
+	// Project restricts the calculation to one project from the projects config section.
+	Project string
+}
+
+// fileUpdate is a file and the editors to run on it, in order.
+type fileUpdate struct {
+	name    string
+	editors []versionEditor
+}
+
+// RunUpdateFiles sets the calculated version in the configured manifests and
+// rule files of the repository at path. Files whose content would not change are
+// left untouched.
+func RunUpdateFiles(fsys fs.Filesystem, out io.Writer, repoPath string, opts UpdateFilesOptions) error {
+	config, r, branchName, err := openRepository(fsys, repoPath)
+	if err != nil {
+		return err
+	}
+	updates, err := plannedUpdates(fsys, repoPath, config.UpdateFiles)
+	if err != nil {
+		return err
+	}
+	result, err := calculateProject(r, config, branchName, opts.Project)
+	if err != nil {
+		return err
+	}
+	version := buildVersionVariables(result).FullSemVer
+
+	for _, update := range updates {
+		fileName := filepath.Join(repoPath, update.name)
+		data, err := fsys.ReadFile(fileName)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", update.name, err)
+		}
+		updated := data
+		for _, edit := range update.editors {
+			if updated, err = edit(updated, version); err != nil {
+				return fmt.Errorf("failed to update %s: %w", update.name, err)
+			}
+		}
+
+		switch {
+		case bytes.Equal(data, updated):
+			_, err = fmt.Fprintf(out, "%s is up to date\n", update.name)
+		case opts.DryRun:
+			err = writeLineDiff(out, update.name, data, updated)
+		default:
+			if err := fsys.WriteFile(fileName, updated, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", update.name, err)
+			}
+			_, err = fmt.Fprintf(out, "Updated %s to %s\n", update.name, version)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plannedUpdates lists the files to update. Without configured files or rules, the
+// manifests present at the repository root are updated.
+func plannedUpdates(fsys fs.Filesystem, repoPath string, config gitversion.UpdateFilesConfig) ([]*fileUpdate, error) {
+	var updates []*fileUpdate
+	byName := make(map[string]*fileUpdate)
+	add := func(name string, editor versionEditor) {
+		update, ok := byName[name]
+		if !ok {
+			update = &fileUpdate{name: name}
+			byName[name] = update
+			updates = append(updates, update)
+		}
+		update.editors = append(update.editors, editor)
+	}
+
+	if len(config.Files) == 0 && len(config.Rules) == 0 {
+		for _, name := range []string{"package.json", "Chart.yaml", "Cargo.toml", "pom.xml", "pyproject.toml"} {
+			exists, err := fsys.Exists(filepath.Join(repoPath, name))
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				add(name, manifestEditors[name])
+			}
+		}
+		if len(updates) == 0 {
+			return nil, fmt.Errorf("no files to update: no manifest found and no update-files configured")
+		}
+		return updates, nil
+	}
+
+	for _, name := range config.Files {
+		editor, ok := manifestEditors[path.Base(filepath.ToSlash(name))]
+		if !ok {
+			return nil, fmt.Errorf("unsupported manifest %s: use an update-files rule instead", name)
+		}
+		add(name, editor)
+	}
+	for i, rule := range config.Rules {
+		editor, err := regexEditor(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid update-files rules[%d]: %w", i, err)
+		}
+		add(rule.File, editor)
+	}
+	return updates, nil
+}
+
+// writeLineDiff prints the changed lines of a file in unified diff style, one hunk
+// per run of changed lines and without context. Edits may add or remove lines, as
+// multiline update-files rules can.
+func writeLineDiff(out io.Writer, name string, before, after []byte) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+	oldLine, newLine := 1, 1
+	var removed, added []string
+	flush := func() {
+		if len(removed) == 0 && len(added) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldLine, len(removed)), hunkRange(newLine, len(added)))
+		for _, line := range removed {
+			fmt.Fprintf(&b, "-%s\n", line)
+		}
+		for _, line := range added {
+			fmt.Fprintf(&b, "+%s\n", line)
+		}
+		oldLine += len(removed)
+		newLine += len(added)
+		removed, added = nil, nil
+	}
+	for _, d := range diff.Do(string(before), string(after)) {
+		lines := diffLines(d.Text)
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			flush()
+			oldLine += len(lines)
+			newLine += len(lines)
+		case diffmatchpatch.DiffDelete:
+			removed = append(removed, lines...)
+		case diffmatchpatch.DiffInsert:
+			added = append(added, lines...)
+		}
+	}
+	flush()
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// diffLines splits the text of a line diff into its lines, without line breaks.
+func diffLines(text string) []string {
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// hunkRange formats the start and length of a hunk side like diff -u, which
+// leaves out a length of 1 and gives the line before an empty side.
+func hunkRange(start, n int) string {
+	switch n {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprint(start)
+	default:
+		return fmt.Sprintf("%d,%d", start, n)
+	}
+}
@@ -13,6 +13,7 @@ import (
 type Filesystem interface {
 	ReadFile(name string) ([]byte, error)
 	WriteFile(name string, data []byte, perm fs.FileMode) error
+	MkdirAll(path string, perm fs.FileMode) error
 	Exists(name string) (bool, error)
 }
 
@@ -34,6 +35,11 @@ func (fs *OsFs) WriteFile(name string, data []byte, perm fs.FileMode) error {
 	return os.WriteFile(name, data, perm)
 }
 
+// MkdirAll creates a directory along with any missing parents.
+func (fs *OsFs) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
 // Exists checks if a file or directory exists.
 func (fs *OsFs) Exists(name string) (bool, error) {
 	_, err := os.Stat(name)
@@ -97,3 +103,8 @@ func (b *BillyWrappedFs) WriteFile(name string, data []byte, perm fs.FileMode) (
 	_, err = file.Write(data)
 	return err
 }
+
+// MkdirAll creates a directory along with any missing parents.
+func (b *BillyWrappedFs) MkdirAll(path string, perm fs.FileMode) error {
+	return b.fs.MkdirAll(path, perm)
+}
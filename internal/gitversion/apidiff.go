@@ -0,0 +1,358 @@
+package gitversion
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// API compatibility levels reported by the api-diff strategy.
+const (
+	APIBreaking = "breaking"
+	APIAdditive = "additive"
+	APINone     = "none"
+)
+
+// APIDiff is the difference between the exported Go API at the base version and at HEAD.
+type APIDiff struct {
+	// Compatibility is APIBreaking, APIAdditive or APINone.
+	Compatibility string
+	// Incompatible lists removed or changed symbols, e.g. "example.com/m/pkg.Func".
+	Incompatible []string
+	// Added lists new symbols.
+	Added []string
+}
+
+func (d *APIDiff) bump() semverBump {
+	switch d.Compatibility {
+	case APIBreaking:
+		return majorBump
+	case APIAdditive:
+		return minorBump
+	default:
+		return noBump
+	}
+}
+
+// APIDiffStrategy compares the exported API of the Go packages in the module at the
+// base version's tag commit with the API at HEAD. It never produces a version by
+// itself; increment-from-commits combines its result with the commit message bump,
+// so it has to come after find-latest-tag and before increment-from-commits.
+type APIDiffStrategy struct{}
+
+// Execute runs the APIDiffStrategy to classify the API changes since the base version.
+func (s *APIDiffStrategy) Execute(ctx *VersionContext) (bool, error) {
+	if ctx.BaseVersionCommit == nil || ctx.NextVersion != nil {
+		return false, nil
+	}
+
+	if ctx.HeadCommit == nil {
+		return false, errors.New("repository has no HEAD commit")
+	}
+
+	baseAPI, err := moduleAPI(ctx.BaseVersionCommit, ctx.ProjectPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read API at %s: %w", ctx.BaseVersionCommit.Hash, err)
+	}
+	headAPI, err := moduleAPI(ctx.HeadCommit, ctx.ProjectPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read API at HEAD: %w", err)
+	}
+
+	ctx.APIDiff = diffAPI(baseAPI, headAPI)
+	return false, nil
+}
+
+func diffAPI(base, head map[string]string) *APIDiff {
+	diff := &APIDiff{Compatibility: APINone}
+	for symbol, signature := range base {
+		if headSignature, ok := head[symbol]; !ok || headSignature != signature {
+			diff.Incompatible = append(diff.Incompatible, symbol)
+		}
+	}
+	for symbol := range head {
+		if _, ok := base[symbol]; !ok {
+			diff.Added = append(diff.Added, symbol)
+		}
+	}
+	sort.Strings(diff.Incompatible)
+	sort.Strings(diff.Added)
+
+	if len(diff.Incompatible) > 0 {
+		diff.Compatibility = APIBreaking
+	} else if len(diff.Added) > 0 {
+		diff.Compatibility = APIAdditive
+	}
+	return diff
+}
+
+// sourcePackage is the Go source of one package directory read from a commit.
+type sourcePackage struct {
+	importPath string
+	files      map[string][]byte // file name to content
+	public     bool              // false for internal and main packages
+}
+
+// moduleAPI type-checks the packages of the Go module in dir of a commit's tree and
+// returns the signature of every exported symbol, keyed by "importpath.Name" (and
+// "importpath.Type.Member" for fields and methods). Type errors, such as imports
+// that cannot be resolved, are tolerated: affected types are reported the same way
+// at both commits, so they do not show up as differences.
+func moduleAPI(commit *object.Commit, dir string) (map[string]string, error) {
+	packages, err := readSourcePackages(commit, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	imp := &treeImporter{
+		fset:     fset,
+		sources:  packages,
+		checked:  make(map[string]*types.Package),
+		fallback: importer.Default(),
+	}
+
+	api := make(map[string]string)
+	for importPath, src := range packages {
+		if !src.public {
+			continue
+		}
+		pkg, err := imp.Import(importPath)
+		if err != nil {
+			return nil, err
+		}
+		addExportedAPI(api, pkg)
+	}
+	return api, nil
+}
+
+// readSourcePackages reads the non-test Go files of a module from a commit's tree,
+// grouped by import path. Like the go command, it skips vendor and testdata
+// directories, directories starting with "." or "_", and nested modules, and it
+// only keeps files whose build constraints match the current platform.
+func readSourcePackages(commit *object.Commit, dir string) (map[string]*sourcePackage, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		tree, err = tree.Tree(dir)
+		if errors.Is(err, object.ErrDirectoryNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	modulePath := dir
+	if modFile, err := readGoMod(commit, dir); err != nil {
+		return nil, err
+	} else if modFile != nil && modFile.Module != nil {
+		modulePath = modFile.Module.Mod.Path
+	}
+
+	files := make(map[string][]byte)
+	nestedModules := make(map[string]bool)
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !entry.Mode.IsFile() || ignoredByGoCommand(path.Dir(name)) {
+			continue
+		}
+		if path.Base(name) == "go.mod" && path.Dir(name) != "." {
+			nestedModules[path.Dir(name)] = true
+			continue
+		}
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := tree.TreeEntryFile(&entry)
+		if err != nil {
+			return nil, err
+		}
+		content, err := file.Contents()
+		if err != nil {
+			return nil, err
+		}
+		files[name] = []byte(content)
+	}
+
+	ctxt := build.Default
+	ctxt.CgoEnabled = false
+	ctxt.JoinPath = path.Join
+	ctxt.OpenFile = func(name string) (io.ReadCloser, error) {
+		content, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("file not found: %s", name)
+		}
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
+
+	packages := make(map[string]*sourcePackage)
+	for name, content := range files {
+		pkgDir := path.Dir(name)
+		if inNestedModule(pkgDir, nestedModules) {
+			continue
+		}
+		if ok, err := ctxt.MatchFile(pkgDir, path.Base(name)); err != nil || !ok {
+			continue
+		}
+		importPath := modulePath
+		if pkgDir != "." {
+			importPath = path.Join(modulePath, pkgDir)
+		}
+		src, ok := packages[importPath]
+		if !ok {
+			src = &sourcePackage{
+				importPath: importPath,
+				files:      make(map[string][]byte),
+				public:     !isInternalPath(pkgDir),
+			}
+			packages[importPath] = src
+		}
+		src.files[path.Join(dir, name)] = content
+	}
+	return packages, nil
+}
+
+func inNestedModule(dir string, nestedModules map[string]bool) bool {
+	for nested := range nestedModules {
+		if isInDir(dir, nested) {
+			return true
+		}
+	}
+	return false
+}
+
+func isInternalPath(dir string) bool {
+	for _, elem := range strings.Split(dir, "/") {
+		if elem == "internal" {
+			return true
+		}
+	}
+	return false
+}
+
+// treeImporter type-checks packages of the module from source and falls back to
+// the default importer, and then to an empty package, for everything else.
+type treeImporter struct {
+	fset     *token.FileSet
+	sources  map[string]*sourcePackage
+	checked  map[string]*types.Package
+	fallback types.Importer
+}
+
+func (imp *treeImporter) Import(importPath string) (*types.Package, error) {
+	if pkg, ok := imp.checked[importPath]; ok {
+		return pkg, nil
+	}
+
+	src, ok := imp.sources[importPath]
+	if !ok {
+		if pkg, err := imp.fallback.Import(importPath); err == nil {
+			imp.checked[importPath] = pkg
+			return pkg, nil
+		}
+		pkg := types.NewPackage(importPath, path.Base(importPath))
+		pkg.MarkComplete()
+		imp.checked[importPath] = pkg
+		return pkg, nil
+	}
+
+	names := make([]string, 0, len(src.files))
+	for name := range src.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var files []*ast.File
+	for _, name := range names {
+		file, err := parser.ParseFile(imp.fset, name, src.files[name], parser.SkipObjectResolution)
+		if err != nil {
+			continue // a file that does not parse contributes nothing to the API
+		}
+		files = append(files, file)
+	}
+	if len(files) > 0 && files[0].Name.Name == "main" {
+		src.public = false
+	}
+
+	// Guard against import cycles while this package is being checked.
+	placeholder := types.NewPackage(importPath, path.Base(importPath))
+	imp.checked[importPath] = placeholder
+
+	conf := types.Config{
+		Importer:                 imp,
+		Error:                    func(error) {},
+		DisableUnusedImportCheck: true,
+	}
+	pkg, _ := conf.Check(importPath, imp.fset, files, nil)
+	if pkg == nil {
+		pkg = placeholder
+	}
+	imp.checked[importPath] = pkg
+	return pkg, nil
+}
+
+// addExportedAPI records the signature of every exported symbol of pkg. Struct
+// fields are recorded one by one so that adding a field counts as an addition
+// rather than a change of the struct type.
+func addExportedAPI(api map[string]string, pkg *types.Package) {
+	if pkg.Name() == "main" {
+		return
+	}
+	qualifier := types.RelativeTo(pkg)
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		key := pkg.Path() + "." + name
+
+		typeName, ok := obj.(*types.TypeName)
+		if !ok || typeName.IsAlias() {
+			api[key] = types.ObjectString(obj, qualifier)
+			continue
+		}
+
+		if st, ok := typeName.Type().Underlying().(*types.Struct); ok {
+			api[key] = "type " + name + " struct"
+			for i := 0; i < st.NumFields(); i++ {
+				if field := st.Field(i); field.Exported() {
+					api[key+"."+field.Name()] = types.ObjectString(field, qualifier)
+				}
+			}
+		} else {
+			api[key] = types.ObjectString(obj, qualifier)
+		}
+
+		if named, ok := typeName.Type().(*types.Named); ok {
+			for i := 0; i < named.NumMethods(); i++ {
+				if method := named.Method(i); method.Exported() {
+					api[key+"."+method.Name()] = types.ObjectString(method, qualifier)
+				}
+			}
+		}
+	}
+}
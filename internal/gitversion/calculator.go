@@ -15,41 +15,281 @@ import (
 
 // CalculateNextVersion calculates the next version based on the commit history using a strategy-based approach.
 func CalculateNextVersion(r *git.Repository, config *Config, currentBranchName string) (*semver.Version, int, error) {
-	strategies, err := BuildStrategies(config, currentBranchName)
+	ctx, err := Calculate(r, config, currentBranchName)
 	if err != nil {
 		return nil, 0, err
 	}
+	return ctx.NextVersion, ctx.CommitsSinceLastTag, nil
+}
 
-	executor := NewStrategyExecutor(strategies)
-	ctx := &VersionContext{
+// Calculate runs the configured strategies and returns the resulting context.
+// ctx.NextVersion is always set on success, falling back to the base version or 0.1.0.
+func Calculate(r *git.Repository, config *Config, currentBranchName string) (*VersionContext, error) {
+	return calculate(&VersionContext{
 		Repository:        r,
 		Config:            config,
 		CurrentBranchName: currentBranchName,
+	})
+}
+
+// CalculateProject calculates the next version of a single monorepo project. Only
+// tags matching the project's tag prefix and commits touching its path are considered.
+func CalculateProject(r *git.Repository, config *Config, currentBranchName, projectName string) (*VersionContext, error) {
+	ctx, err := newProjectContext(r, config, currentBranchName, projectName)
+	if err != nil {
+		return nil, err
+	}
+	return calculate(ctx)
+}
+
+// Explain is Calculate, or CalculateProject when projectName is set, recording
+// the decisions of the calculation in the context's Trace.
+func Explain(r *git.Repository, config *Config, currentBranchName, projectName string) (*VersionContext, error) {
+	ctx := &VersionContext{Repository: r, Config: config, CurrentBranchName: currentBranchName}
+	if projectName != "" {
+		var err error
+		if ctx, err = newProjectContext(r, config, currentBranchName, projectName); err != nil {
+			return nil, err
+		}
+	}
+	ctx.Trace = &Trace{}
+	return calculate(ctx)
+}
+
+// CalculateRange calculates the commits between two released versions, identified
+// by their tags, of the repository or of a project when projectName is set. An
+// empty to means HEAD and the next version, and an empty from means the base
+// version found by the configured strategies.
+func CalculateRange(r *git.Repository, config *Config, currentBranchName, projectName, from, to string) (*VersionContext, error) {
+	if to != "" && from == "" {
+		return nil, errors.New("a range ending at a version needs a start version as well")
+	}
+	ctx := &VersionContext{Repository: r, Config: config, CurrentBranchName: currentBranchName}
+	if projectName != "" {
+		var err error
+		if ctx, err = newProjectContext(r, config, currentBranchName, projectName); err != nil {
+			return nil, err
+		}
+	}
+
+	tags, err := NewTagIndex(r)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Tags = tags
+	// The ends of the range are subject to the same checks as base versions.
+	if ctx.Config, err = withSkippedVersions(r, ctx.Config, ctx.ProjectPath); err != nil {
+		return nil, err
+	}
+	if ctx.Config, err = withTrustedTags(ctx); err != nil {
+		return nil, err
+	}
+	var toVersion *semver.Version
+	if from != "" {
+		if ctx.BaseVersion, ctx.BaseVersionCommit, err = findTaggedVersion(ctx.Config, tags, from); err != nil {
+			return nil, err
+		}
+	}
+	if to != "" {
+		if toVersion, ctx.HeadCommit, err = findTaggedVersion(ctx.Config, tags, to); err != nil {
+			return nil, err
+		}
+	}
+
+	if ctx, err = calculate(ctx); err != nil {
+		return nil, err
+	}
+	if toVersion != nil {
+		ctx.NextVersion = toVersion
+	}
+	return ctx, nil
+}
+
+// findTaggedVersion returns the version and commit of the tag for a version. A
+// retracted or yanked version and a tag without a trusted signature are errors.
+func findTaggedVersion(config *Config, tags *TagIndex, version string) (*semver.Version, *object.Commit, error) {
+	want, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid version %q: %w", version, err)
+	}
+	if config.skipped.skips(want) {
+		return nil, nil, fmt.Errorf("version %s is retracted or yanked", want)
+	}
+	var untrusted string
+	for _, tag := range tags.Tags {
+		v, ok := tagVersion(config, tag.Name)
+		if !ok || !v.Equal(want) {
+			continue
+		}
+		if !config.trusts(tag.Name) {
+			untrusted = tag.Name
+			continue
+		}
+		return v, tag.Commit, nil
+	}
+	if untrusted != "" {
+		return nil, nil, fmt.Errorf("tag %s of version %s has no trusted signature", untrusted, want)
+	}
+	return nil, nil, fmt.Errorf("no tag found for version %s", want)
+}
+
+func newProjectContext(r *git.Repository, config *Config, currentBranchName, projectName string) (*VersionContext, error) {
+	config, err := withGoModuleProjects(r, config)
+	if err != nil {
+		return nil, err
+	}
+	projectConfig, err := config.ForProject(projectName)
+	if err != nil {
+		return nil, err
+	}
+	return &VersionContext{
+		Repository:        r,
+		Config:            projectConfig,
+		CurrentBranchName: currentBranchName,
+		ProjectName:       projectName,
+		ProjectPath:       cleanProjectPath(config.Projects[projectName].Path),
+		ProjectExcludes:   config.Projects[projectName].ExcludePaths,
+	}, nil
+}
+
+// CalculateAllProjects calculates every configured monorepo project, one after
+// another: go-git repositories are not safe for concurrent use. The projects share
+// a single tag index; results are returned in the order of ProjectNames. Projects
+// whose go.mod depends on a changed project get at least a patch bump.
+func CalculateAllProjects(r *git.Repository, config *Config, currentBranchName string) ([]*VersionContext, error) {
+	config, err := withGoModuleProjects(r, config)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := NewTagIndex(r)
+	if err != nil {
+		return nil, err
+	}
+
+	names := config.ProjectNames()
+	contexts := make([]*VersionContext, len(names))
+	for i, name := range names {
+		projectConfig, err := config.ForProject(name)
+		if err != nil {
+			return nil, err
+		}
+		contexts[i] = &VersionContext{
+			Repository:        r,
+			Config:            projectConfig,
+			CurrentBranchName: currentBranchName,
+			ProjectName:       name,
+			ProjectPath:       cleanProjectPath(config.Projects[name].Path),
+			ProjectExcludes:   config.Projects[name].ExcludePaths,
+			Tags:              tags,
+		}
+	}
+
+	results := make([]*VersionContext, len(names))
+	for i, ctx := range contexts {
+		if results[i], err = calculate(ctx); err != nil {
+			return nil, fmt.Errorf("project %s: %w", names[i], err)
+		}
+	}
+
+	if err := propagateDependencyBumps(results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Changed reports whether the calculation produced a version that has not been
+// released yet, i.e. there is no base version or the next version differs from it.
+func (ctx *VersionContext) Changed() bool {
+	return ctx.BaseVersion == nil || !ctx.NextVersion.Equal(ctx.BaseVersion)
+}
+
+func calculate(ctx *VersionContext) (*VersionContext, error) {
+	config, err := withSkippedVersions(ctx.Repository, ctx.Config, ctx.ProjectPath)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Config = config
+	if ctx.Config, err = withTrustedTags(ctx); err != nil {
+		return nil, err
+	}
+	if ctx.Trace != nil {
+		ctx.Trace.Branch = ctx.CurrentBranchName
+		ctx.Trace.BranchPattern, _ = ctx.Config.MatchBranchConfig(ctx.CurrentBranchName)
+	}
+
+	if ctx.HeadCommit == nil {
+		head, err := ctx.Repository.Head()
+		if err != nil && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return nil, err
+		}
+		if head != nil {
+			if ctx.HeadCommit, err = ctx.Repository.CommitObject(head.Hash()); err != nil {
+				return nil, err
+			}
+		}
 	}
 
+	strategies, err := BuildStrategies(ctx.Config, ctx.CurrentBranchName)
+	if err != nil {
+		return nil, err
+	}
+
+	executor := NewStrategyExecutor(strategies)
+
 	if err := executor.ExecuteStrategies(ctx); err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+	ctx.Warnings = append(ctx.Warnings, ctx.Config.untrustedTagWarnings()...)
+	if ctx.Issues, err = findIssueReferences(ctx); err != nil {
+		return nil, err
+	}
+	if ctx.Trace != nil {
+		if ctx.BaseVersion != nil {
+			ctx.Trace.BaseVersion = ctx.BaseVersion.String()
+		}
+		if ctx.BaseVersionCommit != nil {
+			ctx.Trace.BaseCommit = ctx.BaseVersionCommit.Hash.String()
+		}
+		defer func() { ctx.Trace.NextVersion = ctx.NextVersion.String() }()
 	}
 
 	if ctx.NextVersion == nil {
 		if ctx.BaseVersion != nil {
-			return ctx.BaseVersion, ctx.CommitsSinceLastTag, nil
+			ctx.NextVersion = ctx.BaseVersion
+			return ctx, nil
 		}
 		// Fallback to 0.1.0 if no version could be determined.
-		v := semver.MustParse("0.1.0")
-		return v, 0, nil
+		ctx.NextVersion = semver.MustParse("0.1.0")
+		ctx.CommitsSinceLastTag = 0
+		ctx.Trace.setBump(-1, "no base version; falling back to 0.1.0")
 	}
 
-	return ctx.NextVersion, ctx.CommitsSinceLastTag, nil
+	skipPastSkippedVersions(ctx)
+	checkGoModuleMajor(ctx)
+	return ctx, nil
 }
 
 // FindLatestVersion finds the latest semantic version tag in the repository.
 // It first checks the source branches of the current branch, if any.
 // If no version is found on the source branches, it searches all tags.
 func FindLatestVersion(r *git.Repository, config *Config, currentBranchName string) (*semver.Version, *object.Commit, error) {
+	config, err := withSkippedVersions(r, config, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	tags, err := NewTagIndex(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return findLatestVersion(r, config, currentBranchName, tags, nil)
+}
+
+// findLatestVersion records the tags it considers in trace, if it is set.
+func findLatestVersion(r *git.Repository, config *Config, currentBranchName string, tags *TagIndex, trace *Trace) (*semver.Version, *object.Commit, error) {
 	branchConfig := config.GetBranchConfig(currentBranchName)
 	if branchConfig != nil && len(branchConfig.SourceBranches) > 0 {
-		latestVersion, latestTagCommit, err := findVersionOnBranches(r, config, branchConfig.SourceBranches)
+		latestVersion, latestTagCommit, err := findVersionOnBranches(r, config, branchConfig.SourceBranches, tags, trace)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -58,12 +298,94 @@ func FindLatestVersion(r *git.Repository, config *Config, currentBranchName stri
 		}
 	}
 
-	return findLatestVersionAllTags(r, config)
+	return findLatestVersionAllTags(config, tags, trace)
+}
+
+// TagIndex holds every tag in a repository resolved to the commit it points at.
+// It is built once and can be shared by the calculations of several projects.
+type TagIndex struct {
+	Tags     []IndexedTag
+	byCommit map[plumbing.Hash][]IndexedTag
+}
+
+// IndexedTag is a tag together with the commit it points at.
+type IndexedTag struct {
+	Name   string
+	Ref    *plumbing.Reference
+	Commit *object.Commit
+}
+
+// NewTagIndex reads and resolves all tags of the repository. Tags that do not
+// point at a commit are skipped.
+func NewTagIndex(r *git.Repository) (*TagIndex, error) {
+	refs, err := getTags(r)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &TagIndex{byCommit: make(map[plumbing.Hash][]IndexedTag)}
+	for _, ref := range refs {
+		commit, err := getCommitFromTag(r, ref)
+		if err != nil {
+			// Cannot resolve tag, skip
+			continue
+		}
+		tag := IndexedTag{Name: ref.Name().Short(), Ref: ref, Commit: commit}
+		index.Tags = append(index.Tags, tag)
+		index.byCommit[commit.Hash] = append(index.byCommit[commit.Hash], tag)
+	}
+	return index, nil
+}
+
+// TagsOn returns the tags pointing at the given commit.
+func (t *TagIndex) TagsOn(hash plumbing.Hash) []IndexedTag {
+	return t.byCommit[hash]
 }
 
-func findVersionOnBranches(r *git.Repository, config *Config, branchNames []string) (*semver.Version, *object.Commit, error) {
-	var versions []*semver.Version
-	tagCommitMap := make(map[*semver.Version]*object.Commit)
+// tagVersion strips the configured tag prefix from a tag name and parses the rest
+// as a semantic version. It does not check signatures; see Config.trusts.
+func tagVersion(config *Config, tagName string) (*semver.Version, bool) {
+	v, rejected := checkTagVersion(config, tagName)
+	return v, rejected == ""
+}
+
+// checkTagVersion is tagVersion returning the reason a tag is rejected, or "".
+func checkTagVersion(config *Config, tagName string) (*semver.Version, string) {
+	prefix := config.TagPrefix
+	if prefix == "" {
+		prefix = defaultTagPrefix
+	}
+	re, err := regexp.Compile("^" + prefix)
+	if err != nil {
+		return nil, "invalid tag-prefix" // skip invalid prefix
+	}
+	if !re.MatchString(tagName) {
+		return nil, "does not match tag-prefix" // skip tags that don't match prefix
+	}
+	v, err := semver.NewVersion(re.ReplaceAllString(tagName, ""))
+	if err != nil {
+		return nil, "not a semantic version"
+	}
+	if config.goModule.Path != "" && !config.goModule.acceptsVersion(v) {
+		// skip versions the go command would reject for this module path
+		return v, "not a valid version for module " + config.goModule.Path
+	}
+	if config.skipped.skips(v) {
+		return v, "retracted or yanked" // never build on a retracted or yanked version
+	}
+	return v, ""
+}
+
+// acceptsUnprefixedTags reports whether tags on source branches that do not match
+// the tag prefix are taken as versions as they are. Only the default prefix allows
+// it: a custom prefix, such as a project's, is what tells its tags apart.
+func (c *Config) acceptsUnprefixedTags() bool {
+	return c.TagPrefix == "" || c.TagPrefix == defaultTagPrefix
+}
+
+func findVersionOnBranches(r *git.Repository, config *Config, branchNames []string, tags *TagIndex, trace *Trace) (*semver.Version, *object.Commit, error) {
+	var candidates []tagCandidate
+	var considered []TagTrace
 
 	for _, branchName := range branchNames {
 		branchRef, err := r.Reference(plumbing.NewBranchReferenceName(branchName), true)
@@ -79,42 +401,22 @@ func findVersionOnBranches(r *git.Repository, config *Config, branchNames []stri
 			return nil, nil, err
 		}
 
-		tags, err := getTags(r)
-		if err != nil {
-			return nil, nil, err
-		}
-
-		commitIter, err := r.Log(&git.LogOptions{From: commit.Hash})
-		if err != nil {
-			return nil, nil, err
-		}
-
-		commitTags := make(map[plumbing.Hash][]string)
-		for _, tag := range tags {
-			// TODO: This is inefficient. We should get the commit from the tag ref instead.
-			commitTags[tag.Hash()] = append(commitTags[tag.Hash()], tag.Name().Short()) // tag name as-is
-			// Also store with prefix stripped for lookup
-			prefix := config.TagPrefix
-			if prefix == "" {
-				prefix = "([vV])?"
-			}
-			re, err := regexp.Compile("^" + prefix)
-			if err == nil && re.MatchString(tag.Name().Short()) {
-				stripped := re.ReplaceAllString(tag.Name().Short(), "")
-				commitTags[tag.Hash()] = append(commitTags[tag.Hash()], stripped)
-			}
-
-		}
-
-		err = commitIter.ForEach(func(c *object.Commit) error {
-			if tagNames, ok := commitTags[c.Hash]; ok {
-				for _, tagName := range tagNames {
-					v, err := semver.NewVersion(tagName)
-					if err == nil {
-						versions = append(versions, v)
-						tagCommitMap[v] = c
+		err = object.NewCommitPreorderIter(commit, nil, nil).ForEach(func(c *object.Commit) error {
+			for _, tag := range tags.TagsOn(c.Hash) {
+				v, rejected := checkTagVersion(config, tag.Name)
+				if rejected != "" && config.acceptsUnprefixedTags() {
+					// Tags on source branches are also accepted as-is, without the prefix.
+					if raw, err := semver.NewVersion(tag.Name); err == nil {
+						v, rejected = raw, ""
+						if config.skipped.skips(v) {
+							rejected = "retracted or yanked"
+						}
 					}
 				}
+				if rejected == "" {
+					candidates = append(candidates, tagCandidate{tag.Name, v, c, len(considered)})
+				}
+				considered = append(considered, newTagTrace(tag, "branch "+branchName, v, c, rejected))
 			}
 			return nil
 		})
@@ -123,69 +425,62 @@ func findVersionOnBranches(r *git.Repository, config *Config, branchNames []stri
 		}
 	}
 
-	if len(versions) == 0 {
+	latest := latestTrusted(config, candidates, considered)
+	if latest == nil {
+		trace.addTags(considered, nil, nil)
 		return nil, nil, nil
 	}
-
-	// Sort versions
-	sort.Slice(versions, func(i, j int) bool {
-		return versions[i].LessThan(versions[j])
-	})
-
-	latestVersion := versions[len(versions)-1]
-	return latestVersion, tagCommitMap[latestVersion], nil
+	trace.addTags(considered, latest.version, latest.commit)
+	return latest.version, latest.commit, nil
 }
 
-func findLatestVersionAllTags(r *git.Repository, config *Config) (*semver.Version, *object.Commit, error) {
-	tagRefs, err := r.Tags()
-	if err != nil {
-		return nil, nil, err
-	}
+func findLatestVersionAllTags(config *Config, tags *TagIndex, trace *Trace) (*semver.Version, *object.Commit, error) {
+	var candidates []tagCandidate
+	var considered []TagTrace
 
-	var versions []*semver.Version
-	tagCommitMap := make(map[*semver.Version]*object.Commit)
-
-	err = tagRefs.ForEach(func(ref *plumbing.Reference) error {
-		tagName := ref.Name().Short()
-		prefix := config.TagPrefix
-		if prefix == "" {
-			prefix = "([vV])?"
+	for _, tag := range tags.Tags {
+		v, rejected := checkTagVersion(config, tag.Name)
+		if rejected == "" {
+			candidates = append(candidates, tagCandidate{tag.Name, v, tag.Commit, len(considered)})
 		}
-		re, err := regexp.Compile("^" + prefix)
-		if err != nil {
-			return nil // skip invalid prefix
-		}
-		if !re.MatchString(tagName) {
-			return nil // skip tags that don't match prefix
-		}
-		stripped := re.ReplaceAllString(tagName, "")
-		v, err := semver.NewVersion(stripped)
-		if err == nil {
-			commit, err := getCommitFromTag(r, ref)
-			if err != nil {
-				// Cannot resolve tag, skip
-				return nil
-			}
-			versions = append(versions, v)
-			tagCommitMap[v] = commit
-		}
-		return nil
-	})
-	if err != nil {
-		return nil, nil, err
+		considered = append(considered, newTagTrace(tag, "all tags", v, tag.Commit, rejected))
 	}
 
-	if len(versions) == 0 {
+	latest := latestTrusted(config, candidates, considered)
+	if latest == nil {
+		trace.addTags(considered, nil, nil)
 		return nil, nil, nil
 	}
+	trace.addTags(considered, latest.version, latest.commit)
+	return latest.version, latest.commit, nil
+}
 
-	// Sort versions
-	sort.Slice(versions, func(i, j int) bool {
-		return versions[i].LessThan(versions[j])
-	})
+// tagCandidate is a version tag a search may select, with the index of its
+// entry among the tags the search considered.
+type tagCandidate struct {
+	name    string
+	version *semver.Version
+	commit  *object.Commit
+	trace   int
+}
 
-	latestVersion := versions[len(versions)-1]
-	return latestVersion, tagCommitMap[latestVersion], nil
+// latestTrusted returns the candidate with the highest version whose tag the
+// configuration trusts, or nil. Signatures are only verified from the highest
+// version down to that one; the tags rejected on the way are marked in
+// considered, if it is set.
+func latestTrusted(config *Config, candidates []tagCandidate, considered []TagTrace) *tagCandidate {
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].version.LessThan(candidates[j].version)
+	})
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if config.trusts(candidates[i].name) {
+			return &candidates[i]
+		}
+		if considered != nil {
+			considered[candidates[i].trace].Rejected = "no trusted signature" // require-signed-tags rejected the tag's signature
+		}
+	}
+	return nil
 }
 
 func getCommitFromTag(r *git.Repository, ref *plumbing.Reference) (*object.Commit, error) {
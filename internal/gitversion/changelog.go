@@ -0,0 +1,132 @@
+package gitversion
+
+import (
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ChangelogConfig configures the changelog command.
+type ChangelogConfig struct {
+	// Template is a Go text/template rendering a Changelog.
+	Template string `yaml:"template,omitempty"`
+	// CompareURL is the URL comparing two tags in CHANGELOG.md links, with {from}
+	// and {to} placeholders. It defaults to the GitHub-style compare URL of the
+	// origin remote.
+	CompareURL string `yaml:"compare-url,omitempty"`
+}
+
+// Changelog is the release notes of one version.
+type Changelog struct {
+	Version         string
+	PreviousVersion string // empty for the first release
+	Date            time.Time
+	Sections        []ChangelogSection
+	Issues          []IssueReference // referenced by any commit of the version
+}
+
+// ChangelogSection is a group of changelog entries, such as "Features".
+type ChangelogSection struct {
+	Title   string
+	Entries []ChangelogEntry
+}
+
+// ChangelogEntry is one commit in the release notes.
+type ChangelogEntry struct {
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+	SHA         string
+	ShortSHA    string
+	Issues      []IssueReference
+}
+
+// Changelog section titles, in the order they are listed.
+const (
+	BreakingChangesSection = "Breaking Changes"
+	FeaturesSection        = "Features"
+	BugFixesSection        = "Bug Fixes"
+	PerformanceSection     = "Performance"
+)
+
+// BuildChangelog groups the commits a calculation analysed by the bump each one
+// contributed, so that the notes match the calculated bump: breaking changes,
+// features and fixes are the major, minor and patch commits after path rules,
+// reverted commits and cherry-picked copies are left out, and performance
+// improvements are listed on their own. Entries are listed newest first.
+func BuildChangelog(ctx *VersionContext, version string) (*Changelog, error) {
+	issues, err := newIssueMatcher(ctx.Config.Issues)
+	if err != nil {
+		return nil, err
+	}
+	changelog := &Changelog{Version: version, Issues: ctx.Issues}
+	if ctx.BaseVersion != nil {
+		changelog.PreviousVersion = ctx.BaseVersion.String()
+	}
+	if ctx.HeadCommit != nil {
+		changelog.Date = ctx.HeadCommit.Committer.When
+	}
+
+	entries := make(map[string][]ChangelogEntry)
+	for i := len(ctx.Commits) - 1; i >= 0; i-- {
+		analysed := ctx.Commits[i]
+		parsed := ParseConventionalCommit(analysed.Commit.Message)
+
+		var section string
+		switch {
+		case analysed.Bump == majorBump:
+			section = BreakingChangesSection
+		case analysed.Bump == minorBump:
+			section = FeaturesSection
+		case parsed.Type == "perf":
+			section = PerformanceSection
+		case analysed.Bump == patchBump:
+			section = BugFixesSection
+		default:
+			continue
+		}
+
+		sha := analysed.Commit.Hash.String()
+		entries[section] = append(entries[section], ChangelogEntry{
+			Type:        parsed.Type,
+			Scope:       parsed.Scope,
+			Description: parsed.Description,
+			Breaking:    parsed.Breaking,
+			SHA:         sha,
+			ShortSHA:    sha[:7],
+			Issues:      issues.find(analysed.Commit.Message),
+		})
+	}
+
+	for _, title := range []string{BreakingChangesSection, FeaturesSection, BugFixesSection, PerformanceSection} {
+		if len(entries[title]) > 0 {
+			changelog.Sections = append(changelog.Sections, ChangelogSection{Title: title, Entries: entries[title]})
+		}
+	}
+	return changelog, nil
+}
+
+// BaseVersionTag returns the name of the tag the base version was taken from, or
+// "" when there is no base version.
+func (ctx *VersionContext) BaseVersionTag() (string, error) {
+	if ctx.BaseVersion == nil || ctx.BaseVersionCommit == nil {
+		return "", nil
+	}
+	tags, err := ctx.tagIndex()
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags.TagsOn(ctx.BaseVersionCommit.Hash) {
+		if v, ok := tagVersion(ctx.Config, tag.Name); ok && v.Equal(ctx.BaseVersion) {
+			return tag.Name, nil
+		}
+		if !ctx.Config.acceptsUnprefixedTags() {
+			continue
+		}
+		if v, err := semver.NewVersion(tag.Name); err == nil && v.Equal(ctx.BaseVersion) {
+			return tag.Name, nil // source branch tags are accepted without the prefix
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,78 @@
+package gitversion
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var cherryPickedFromRegex = regexp.MustCompile(`\(cherry picked from commit ([0-9a-fA-F]{7,40})\)`)
+
+// CherryPickConfig selects how cherry-picked copies of a commit are recognised.
+type CherryPickConfig struct {
+	// Trailer matches the "(cherry picked from commit <sha>)" line added by "git cherry-pick -x".
+	Trailer bool `yaml:"trailer,omitempty"`
+	// PatchID matches commits that introduce an identical change, ignoring whitespace.
+	PatchID bool `yaml:"patch-id,omitempty"`
+}
+
+// CherryPickPair is a cherry-picked commit together with the original it copies.
+type CherryPickPair struct {
+	CherryPick *object.Commit
+	Original   *object.Commit
+}
+
+// findCherryPicks finds commits that duplicate an older commit in the same range,
+// per the enabled detection methods. commits must be ordered with the oldest commit
+// first; the oldest copy of a change is treated as the original.
+func findCherryPicks(config CherryPickConfig, commits []*object.Commit, diffs commitDiffs) ([]CherryPickPair, map[plumbing.Hash]bool, error) {
+	duplicates := make(map[plumbing.Hash]bool)
+	if !config.Trailer && !config.PatchID {
+		return nil, duplicates, nil
+	}
+
+	var pairs []CherryPickPair
+	originalOf := make(map[plumbing.Hash]*object.Commit)
+	originalsByPatchID := make(map[plumbing.Hash]*object.Commit)
+	for i, c := range commits {
+		var original *object.Commit
+		if config.Trailer {
+			if matches := cherryPickedFromRegex.FindStringSubmatch(c.Message); matches != nil {
+				source := strings.ToLower(matches[1])
+				for _, older := range commits[:i] {
+					if strings.HasPrefix(older.Hash.String(), source) {
+						original = older
+						break
+					}
+				}
+			}
+		}
+		// Merge commits have no single change to compare, just like "git cherry",
+		// and neither have commits that change no files.
+		if config.PatchID && c.NumParents() <= 1 {
+			id, ok, err := diffs.patchID(c)
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok {
+				if existing, seen := originalsByPatchID[id]; seen && original == nil {
+					original = existing
+				} else if !seen {
+					originalsByPatchID[id] = c
+				}
+			}
+		}
+		if original != nil {
+			// A copy of a copy is tied back to the first commit that made the change.
+			if root, ok := originalOf[original.Hash]; ok {
+				original = root
+			}
+			originalOf[c.Hash] = original
+			duplicates[c.Hash] = true
+			pairs = append(pairs, CherryPickPair{CherryPick: c, Original: original})
+		}
+	}
+	return pairs, duplicates, nil
+}
@@ -1,22 +1,49 @@
 package gitversion
 
-import "regexp"
+import (
+	"regexp"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
 
 // Config represents the structure of the GitVersion.yml file.
 type Config struct {
-	NextVersion             string                  `yaml:"next-version"`
-	MajorVersionBumpMessage string                  `yaml:"major-version-bump-message"`
-	MinorVersionBumpMessage string                  `yaml:"minor-version-bump-message"`
-	PatchVersionBumpMessage string                  `yaml:"patch-version-bump-message"`
-	NoBumpMessage           string                  `yaml:"no-bump-message"`
-	TagPrefix               string                  `yaml:"tag-prefix"`
-	Ignore                  []string                `yaml:"ignore,omitempty"`
-	Increment               string                  `yaml:"increment,omitempty"`
-	TagPreReleaseWeight     map[string]int          `yaml:"tag-pre-release-weight,omitempty"`
-	Strategies              []string                `yaml:"strategies,omitempty"`
-	CommitDateFormat        string                  `yaml:"commit-date-format,omitempty"`
-	MergeMessageFormats     []string                `yaml:"merge-message-formats,omitempty"`
-	Branches                map[string]BranchConfig `yaml:"branches"`
+	NextVersion             string                   `yaml:"next-version"`
+	MajorVersionBumpMessage string                   `yaml:"major-version-bump-message"`
+	MinorVersionBumpMessage string                   `yaml:"minor-version-bump-message"`
+	PatchVersionBumpMessage string                   `yaml:"patch-version-bump-message"`
+	NoBumpMessage           string                   `yaml:"no-bump-message"`
+	TagPrefix               string                   `yaml:"tag-prefix"`
+	Ignore                  IgnoreConfig             `yaml:"ignore,omitempty"`
+	Increment               string                   `yaml:"increment,omitempty"`
+	TagPreReleaseWeight     map[string]int           `yaml:"tag-pre-release-weight,omitempty"`
+	Strategies              []string                 `yaml:"strategies,omitempty"`
+	CommitDateFormat        string                   `yaml:"commit-date-format,omitempty"`
+	MergeMessageFormats     []string                 `yaml:"merge-message-formats,omitempty"`
+	CherryPicks             CherryPickConfig         `yaml:"cherry-picks,omitempty"`
+	PathRules               []PathRule               `yaml:"path-rules,omitempty"`
+	Branches                map[string]BranchConfig  `yaml:"branches"`
+	Projects                map[string]ProjectConfig `yaml:"projects,omitempty"`
+	GoModules               bool                     `yaml:"go-modules,omitempty"`
+	Yanked                  []string                 `yaml:"yanked,omitempty"`
+	LDFlags                 LDFlagsConfig            `yaml:"ldflags,omitempty"`
+	UpdateFiles             UpdateFilesConfig        `yaml:"update-files,omitempty"`
+	Changelog               ChangelogConfig          `yaml:"changelog,omitempty"`
+	Issues                  []IssuePattern           `yaml:"issues,omitempty"`
+	Tag                     TagConfig                `yaml:"tag,omitempty"`
+	RequireSignedTags       bool                     `yaml:"require-signed-tags,omitempty"`
+	TrustedKeys             string                   `yaml:"trusted-keys,omitempty"`
+	TrustedSSHKeys          string                   `yaml:"trusted-ssh-keys,omitempty"`
+
+	goModule GoModule         // set when calculating a project discovered in go-modules mode
+	skipped  *skippedVersions // set for a calculation; see withSkippedVersions
+
+	trustedKeys    openpgp.EntityList // parsed from the trusted-keys file; see SetTrustedKeys
+	trustedSSHKeys string             // the trusted-ssh-keys file; see SetTrustedSSHKeys
+	trust          *tagTrust          // set for a calculation; see withTrustedTags
+
+	defaultBranches map[string]BranchConfig // built-in branches; see LoadConfig
+	sources         map[string]string       // top-level YAML key to SourceFile or SourceDefault
 }
 
 // BranchConfig represents the configuration for a specific branch.
@@ -31,25 +58,64 @@ type BranchConfig struct {
 	PreventIncrement bool     `yaml:"prevent-increment,omitempty"`
 }
 
+// IsRelease reports whether the branch is configured as a release branch.
+func (b *BranchConfig) IsRelease() bool {
+	return b != nil && b.IsReleaseBranch != nil && *b.IsReleaseBranch
+}
+
+// CommitDateLayout returns the configured commit date format, ISO 8601 by default.
+func (c *Config) CommitDateLayout() string {
+	if c.CommitDateFormat == "" {
+		return "2006-01-02T15:04:05Z07:00"
+	}
+	return c.CommitDateFormat
+}
+
 // GetBranchConfig returns the configuration for a specific branch.
 func (c *Config) GetBranchConfig(branchName string) *BranchConfig {
-	if c.Branches == nil {
-		return nil
+	_, config := c.MatchBranchConfig(branchName)
+	return config
+}
+
+// MatchBranchConfig returns the configuration for a specific branch together with
+// the key of the branches section it was found under.
+func (c *Config) MatchBranchConfig(branchName string) (string, *BranchConfig) {
+	pattern, config, _ := c.ResolveBranchConfig(branchName)
+	return pattern, config
+}
+
+// ResolveBranchConfig returns the configuration for a specific branch, the key of
+// the branches section it was found under, and the YAML keys of the settings it
+// inherits from the top level. The longest matching pattern wins; built-in
+// patterns are only used when the file has no branches section.
+func (c *Config) ResolveBranchConfig(branchName string) (string, *BranchConfig, []string) {
+	pattern, config := matchBranches(c.Branches, branchName)
+	if config == nil {
+		pattern, config = matchBranches(c.defaultBranches, branchName)
+	}
+	if config == nil {
+		return "", nil, nil
 	}
+	inherited := config.inherit(c)
+	return pattern, config, inherited
+}
 
+func matchBranches(branches map[string]BranchConfig, branchName string) (string, *BranchConfig) {
 	var bestMatchConfig *BranchConfig
+	var bestMatchPattern string
 	var bestMatchPatternLength = -1
 
-	for pattern, config := range c.Branches {
+	for pattern, config := range branches {
 		re, err := regexp.Compile(pattern)
 		if err != nil {
-			// Invalid regex in config, maybe log it
+			// Invalid regexes are reported by ValidateConfig when the config is loaded.
 			continue
 		}
 		if re.MatchString(branchName) {
 			// This is a match. Is it better than the previous best match?
 			if len(pattern) > bestMatchPatternLength {
 				bestMatchPatternLength = len(pattern)
+				bestMatchPattern = pattern
 				// Important: make a copy of config to avoid capturing loop variable
 				branchConfigCopy := config
 				bestMatchConfig = &branchConfigCopy
@@ -57,5 +123,5 @@ func (c *Config) GetBranchConfig(branchName string) *BranchConfig {
 		}
 	}
 
-	return bestMatchConfig
+	return bestMatchPattern, bestMatchConfig
 }
@@ -1,26 +1,39 @@
 package gitversion
 
-// DefaultConfig is the default configuration for GitVersion.
+// defaultTagPrefix is the tag-prefix of DefaultConfig, matching both v1.2.3 and 1.2.3.
+const defaultTagPrefix = "[vV]?"
+
+// DefaultConfig is the default configuration for GitVersion. LoadConfig layers
+// the repository's GitVersion.yml over it.
 const DefaultConfig = `next-version: 0.1.0
 major-version-bump-message: "^(\\s|\\S)*?(\\+semver:\\s?(breaking|major))"
 minor-version-bump-message: "^(\\s|\\S)*?(\\+semver:\\s?(feature|minor))"
 patch-version-bump-message: "^(\\s|\\S)*?(\\+semver:\\s?(fix|patch))"
 no-bump-message: "^(\\s|\\S)*?(\\+semver:\\s?(none|skip))"
-tag-prefix: '[vV]'
+tag-prefix: '[vV]?'
+increment: Patch
+strategies: [find-latest-tag, increment-from-commits, configured-next-version]
+commit-date-format: "2006-01-02T15:04:05Z07:00"
+merge-message-formats: ["^Merge pull request #", "^Merge branch '", "^Merged in "]
 branches:
-  main:
+  ^(main|master)$:
     tag: ""
-  develop:
+    increment: Inherit
+  ^develop$:
     mode: ContinuousDeployment
     tag: alpha
-  release/*:
+    increment: Inherit
+  ^release/:
     mode: semver-from-branch
     tag: beta
+    increment: Inherit
     is-release-branch: true
-  feature/*:
+  ^feature/:
     tag: use-branch-name
-  hotfix/*:
+    increment: Inherit
+  ^hotfix/:
     mode: semver-from-branch
     tag: beta
+    increment: Inherit
     is-release-branch: true
 `
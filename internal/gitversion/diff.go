@@ -0,0 +1,145 @@
+package gitversion
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitChanges returns the changes a commit introduces relative to its first parent,
+// or relative to an empty tree for a root commit.
+func commitChanges(c *object.Commit) (object.Changes, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return object.DiffTree(parentTree, tree)
+}
+
+// commitDiffs holds the changes of the commits of one walk, computed at most once
+// per commit however many of the ignore rules, project paths, path rules and patch
+// IDs need them.
+type commitDiffs map[plumbing.Hash]*commitDiff
+
+type commitDiff struct {
+	changes object.Changes
+	paths   []string
+}
+
+func (d commitDiffs) diff(c *object.Commit) (*commitDiff, error) {
+	if diff, ok := d[c.Hash]; ok {
+		return diff, nil
+	}
+	changes, err := commitChanges(c)
+	if err != nil {
+		return nil, err
+	}
+	diff := &commitDiff{changes: changes, paths: changedPaths(changes)}
+	d[c.Hash] = diff
+	return diff, nil
+}
+
+// paths returns every path a commit touches relative to its first parent,
+// including both sides of a rename.
+func (d commitDiffs) paths(c *object.Commit) ([]string, error) {
+	diff, err := d.diff(c)
+	if err != nil {
+		return nil, err
+	}
+	return diff.paths, nil
+}
+
+func changedPaths(changes object.Changes) []string {
+	var paths []string
+	for _, change := range changes {
+		if change.From.Name != "" {
+			paths = append(paths, change.From.Name)
+		}
+		if change.To.Name != "" && change.To.Name != change.From.Name {
+			paths = append(paths, change.To.Name)
+		}
+	}
+	return paths
+}
+
+// patchID returns a stable identifier for the change a commit introduces, in the
+// spirit of "git patch-id": whitespace and line numbers are ignored, so the same
+// change applied on top of a different base gets the same ID. Like "git patch-id",
+// it gives no ID, ok false, to a commit that changes no files.
+func (d commitDiffs) patchID(c *object.Commit) (id plumbing.Hash, ok bool, err error) {
+	diff, err := d.diff(c)
+	if err != nil {
+		return plumbing.ZeroHash, false, err
+	}
+	patch, err := diff.changes.Patch()
+	if err != nil {
+		return plumbing.ZeroHash, false, err
+	}
+
+	filePatches := patch.FilePatches()
+	if len(filePatches) == 0 {
+		return plumbing.ZeroHash, false, nil
+	}
+	entries := make([]string, 0, len(filePatches))
+	for _, fp := range filePatches {
+		from, to := fp.Files()
+		var b strings.Builder
+		if from != nil {
+			b.WriteString(from.Path())
+		}
+		b.WriteString("\x00")
+		if to != nil {
+			b.WriteString(to.Path())
+		}
+		b.WriteString("\x00")
+		if fp.IsBinary() && to != nil {
+			b.WriteString(to.Hash().String())
+		}
+		for _, chunk := range fp.Chunks() {
+			if chunk.Type() == fdiff.Equal {
+				continue // context lines do not identify a change
+			}
+			for _, line := range strings.SplitAfter(chunk.Content(), "\n") {
+				if line == "" {
+					continue
+				}
+				fmt.Fprintf(&b, "%d%s\n", chunk.Type(), strings.Map(dropSpace, line))
+			}
+		}
+		entries = append(entries, b.String())
+	}
+	sort.Strings(entries)
+
+	h := sha1.New()
+	for _, e := range entries {
+		h.Write([]byte(e))
+	}
+	copy(id[:], h.Sum(nil))
+	return id, true, nil
+}
+
+func dropSpace(r rune) rune {
+	if unicode.IsSpace(r) {
+		return -1
+	}
+	return r
+}
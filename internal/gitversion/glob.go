@@ -0,0 +1,66 @@
+package gitversion
+
+import (
+	"regexp"
+	"strings"
+)
+
+// compilePathGlob turns a gitignore-style glob into a regular expression matching
+// slash-separated repository paths:
+//   - "*" and "?" match within a single path segment, "**" matches across segments;
+//   - a pattern without a slash, such as "*.md", matches a file or directory name at any depth;
+//   - any other pattern is anchored at the repository root;
+//   - a pattern that names a directory, such as "docs/" or "api", also matches everything below it.
+func compilePathGlob(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimPrefix(pattern, "./")
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("(?:^|/)")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '*' && strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("(?:/.*)?$")
+	return regexp.Compile(b.String())
+}
+
+// compilePathGlobs compiles a list of globs with compilePathGlob.
+func compilePathGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := compilePathGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// matchesAny reports whether any of the expressions matches s.
+func matchesAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,302 @@
+package gitversion
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// readGoMod parses the go.mod file in the given directory of a commit's tree.
+// It returns nil without an error when the directory has no go.mod.
+func readGoMod(commit *object.Commit, dir string) (*modfile.File, error) {
+	name := path.Join(dir, "go.mod")
+	file, err := commit.File(name)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	modFile, err := modfile.Parse(name, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return modFile, nil
+}
+
+// moduleDependencies returns the module paths and local directories a go.mod
+// depends on, through require directives and the old and new sides of replace
+// directives. Local replacement directories are resolved relative to the
+// repository root.
+func moduleDependencies(dir string, modFile *modfile.File) (modulePaths []string, localDirs []string) {
+	for _, req := range modFile.Require {
+		modulePaths = append(modulePaths, req.Mod.Path)
+	}
+	for _, rep := range modFile.Replace {
+		modulePaths = append(modulePaths, rep.Old.Path)
+		if rep.New.Version == "" && modfile.IsDirectoryPath(rep.New.Path) {
+			localDirs = append(localDirs, cleanProjectPath(path.Join(dir, rep.New.Path)))
+		} else {
+			modulePaths = append(modulePaths, rep.New.Path)
+		}
+	}
+	return modulePaths, localDirs
+}
+
+// propagateDependencyBumps gives every project whose go.mod requires or replaces the
+// module of a changed project at least a patch bump, following the dependency graph
+// transitively. results must all be calculations of projects in the same repository.
+func propagateDependencyBumps(results []*VersionContext) error {
+	if len(results) == 0 {
+		return nil
+	}
+	head, err := results[0].Repository.Head()
+	if err != nil {
+		return err
+	}
+	headCommit, err := results[0].Repository.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+
+	byModule := make(map[string]int)
+	byDir := make(map[string]int)
+	modFiles := make([]*modfile.File, len(results))
+	for i, result := range results {
+		modFile, err := readGoMod(headCommit, result.ProjectPath)
+		if err != nil {
+			return fmt.Errorf("project %s: %w", result.ProjectName, err)
+		}
+		if modFile == nil || modFile.Module == nil {
+			continue
+		}
+		modFiles[i] = modFile
+		byModule[modFile.Module.Mod.Path] = i
+		byDir[result.ProjectPath] = i
+	}
+
+	dependencies := make([][]int, len(results))
+	for i, modFile := range modFiles {
+		if modFile == nil {
+			continue
+		}
+		seen := map[int]bool{i: true}
+		modulePaths, localDirs := moduleDependencies(results[i].ProjectPath, modFile)
+		for _, p := range modulePaths {
+			if j, ok := byModule[p]; ok && !seen[j] {
+				seen[j] = true
+				dependencies[i] = append(dependencies[i], j)
+			}
+		}
+		for _, d := range localDirs {
+			if j, ok := byDir[d]; ok && !seen[j] {
+				seen[j] = true
+				dependencies[i] = append(dependencies[i], j)
+			}
+		}
+	}
+
+	// Repeat until nothing changes so that bumps travel along chains of dependents.
+	for propagated := true; propagated; {
+		propagated = false
+		for i, result := range results {
+			if result.Changed() {
+				continue
+			}
+			for _, j := range dependencies[i] {
+				if results[j].Changed() {
+					result.BumpedBy = append(result.BumpedBy, results[j].ProjectName)
+					// The changes of the dependency count as the project's own, so
+					// that it gets the branch's pre-release label too.
+					result.CommitsSinceLastTag = max(result.CommitsSinceLastTag, results[j].CommitsSinceLastTag, 1)
+				}
+			}
+			if len(result.BumpedBy) > 0 {
+				next := result.BaseVersion.IncPatch()
+				result.NextVersion = &next
+				result.Bump = patchBump
+				skipPastSkippedVersions(result)
+				propagated = true
+			}
+		}
+	}
+	return nil
+}
+
+// GoModule is a Go module found in the repository by its go.mod file.
+type GoModule struct {
+	// Dir is the module directory relative to the repository root, "" for the root.
+	Dir string
+	// Path is the module path declared in go.mod.
+	Path string
+}
+
+// TagDir returns the directory part of the module's tags. It is the module
+// directory, except that a major version subdirectory matching the module path
+// suffix (such as "tools/linter/v2" for ".../tools/linter/v2") is left out, as
+// the go command expects.
+func (m GoModule) TagDir() string {
+	_, pathMajor, ok := module.SplitPathVersion(m.Path)
+	if ok && strings.HasPrefix(pathMajor, "/") {
+		if m.Dir == pathMajor[1:] {
+			return ""
+		}
+		if strings.HasSuffix(m.Dir, pathMajor) {
+			return strings.TrimSuffix(m.Dir, pathMajor)
+		}
+	}
+	return m.Dir
+}
+
+// TagPrefix returns the tag prefix regex for the module, e.g. "tools/linter/v".
+func (m GoModule) TagPrefix() string {
+	if dir := m.TagDir(); dir != "" {
+		return regexp.QuoteMeta(dir) + "/v"
+	}
+	return "v"
+}
+
+// DiscoverGoModules finds the Go modules in the tree at HEAD. Like the go command,
+// it skips vendor and testdata directories and directories starting with "." or "_".
+func DiscoverGoModules(r *git.Repository) ([]GoModule, error) {
+	head, err := r.Head()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := r.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var modules []GoModule
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if entry.Mode.IsFile() && path.Base(name) == "go.mod" && !ignoredByGoCommand(path.Dir(name)) {
+			dir := cleanProjectPath(path.Dir(name))
+			modFile, err := readGoMod(commit, dir)
+			if err != nil {
+				return nil, err
+			}
+			if modFile != nil && modFile.Module != nil {
+				modules = append(modules, GoModule{Dir: dir, Path: modFile.Module.Mod.Path})
+			}
+		}
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Dir < modules[j].Dir })
+	return modules, nil
+}
+
+func ignoredByGoCommand(dir string) bool {
+	for _, elem := range strings.Split(dir, "/") {
+		if elem == "vendor" || elem == "testdata" || (elem != "." && (strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// withGoModuleProjects returns the configuration with a project added for every Go
+// module in the repository when go-modules mode is on. Projects are named after the
+// module directory ("." for the root module); explicitly configured projects with
+// the same name win. Each module's directories exclude the modules nested in them.
+func withGoModuleProjects(r *git.Repository, config *Config) (*Config, error) {
+	if !config.GoModules {
+		return config, nil
+	}
+	modules, err := DiscoverGoModules(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover Go modules: %w", err)
+	}
+
+	expanded := *config
+	expanded.Projects = make(map[string]ProjectConfig, len(config.Projects)+len(modules))
+	for name, project := range config.Projects {
+		expanded.Projects[name] = project
+	}
+	for _, m := range modules {
+		name := m.Dir
+		if name == "" {
+			name = "."
+		}
+		if _, ok := expanded.Projects[name]; ok {
+			continue
+		}
+		project := ProjectConfig{Path: m.Dir, TagPrefix: m.TagPrefix(), goModule: m}
+		for _, nested := range modules {
+			if nested.Dir != m.Dir && isInDir(nested.Dir, m.Dir) {
+				project.ExcludePaths = append(project.ExcludePaths, nested.Dir)
+			}
+		}
+		expanded.Projects[name] = project
+	}
+	return &expanded, nil
+}
+
+// acceptsVersion reports whether a version is valid for the module path, e.g. only
+// v2.x.y for a module path ending in /v2, so that modules sharing a tag prefix
+// through a major version subdirectory do not see each other's tags.
+func (m GoModule) acceptsVersion(v *semver.Version) bool {
+	_, pathMajor, ok := module.SplitPathVersion(m.Path)
+	if !ok {
+		return true
+	}
+	return module.CheckPathMajor("v"+v.String(), pathMajor) == nil
+}
+
+// checkGoModuleMajor warns when the calculated major version does not match the
+// module path's major version suffix.
+func checkGoModuleMajor(ctx *VersionContext) {
+	m := ctx.Config.goModule
+	if m.Path == "" || ctx.NextVersion == nil {
+		return
+	}
+	_, pathMajor, ok := module.SplitPathVersion(m.Path)
+	if !ok {
+		return
+	}
+	if err := module.CheckPathMajor("v"+ctx.NextVersion.String(), pathMajor); err != nil {
+		want := fmt.Sprintf("%s/v%d", m.Path, ctx.NextVersion.Major())
+		if pathMajor != "" {
+			want = strings.TrimSuffix(m.Path, pathMajor)
+			if ctx.NextVersion.Major() >= 2 {
+				want += fmt.Sprintf("/v%d", ctx.NextVersion.Major())
+			}
+		}
+		ctx.Warnings = append(ctx.Warnings, fmt.Sprintf(
+			"calculated version %s does not match module path %s in %s; the module path should be %s",
+			ctx.NextVersion, m.Path, path.Join(m.Dir, "go.mod"), want))
+	}
+}
@@ -0,0 +1,149 @@
+package gitversion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"gopkg.in/yaml.v3"
+)
+
+// IgnoreConfig selects commits that are skipped for both bump analysis and commit counting.
+type IgnoreConfig struct {
+	// SHAs lists full commit SHAs or unambiguous SHA prefixes.
+	SHAs []string `yaml:"sha,omitempty"`
+	// CommitsBefore ignores commits committed before this date (RFC 3339 or YYYY-MM-DD).
+	CommitsBefore string `yaml:"commits-before,omitempty"`
+	// Paths ignores commits that only touch files matching these globs.
+	Paths []string `yaml:"paths,omitempty"`
+	// AuthorEmails and CommitterEmails ignore commits whose author or committer
+	// email matches one of these regexes.
+	AuthorEmails    []string `yaml:"author-emails,omitempty"`
+	CommitterEmails []string `yaml:"committer-emails,omitempty"`
+	// Messages ignores commits whose message matches one of these regexes.
+	Messages []string `yaml:"messages,omitempty"`
+}
+
+// UnmarshalYAML accepts either an ignore block or, for older configs, a plain list of SHAs.
+func (c *IgnoreConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		*c = IgnoreConfig{}
+		return value.Decode(&c.SHAs)
+	}
+	type plain IgnoreConfig
+	return value.Decode((*plain)(c))
+}
+
+// IsZero reports whether no ignore rule is configured.
+func (c IgnoreConfig) IsZero() bool {
+	return len(c.SHAs) == 0 && c.CommitsBefore == "" && len(c.Paths) == 0 &&
+		len(c.AuthorEmails) == 0 && len(c.CommitterEmails) == 0 && len(c.Messages) == 0
+}
+
+// commitFilter is an IgnoreConfig compiled once per calculation.
+type commitFilter struct {
+	fullSHAs        map[string]bool
+	shaPrefixes     []string
+	before          time.Time
+	paths           []*regexp.Regexp
+	authorEmails    []*regexp.Regexp
+	committerEmails []*regexp.Regexp
+	messages        []*regexp.Regexp
+}
+
+func newCommitFilter(config IgnoreConfig) (*commitFilter, error) {
+	f := &commitFilter{fullSHAs: make(map[string]bool)}
+	for _, sha := range config.SHAs {
+		sha = strings.ToLower(strings.TrimSpace(sha))
+		if len(sha) == 40 {
+			f.fullSHAs[sha] = true
+		} else if sha != "" {
+			f.shaPrefixes = append(f.shaPrefixes, sha)
+		}
+	}
+
+	if config.CommitsBefore != "" {
+		before, err := parseIgnoreDate(config.CommitsBefore)
+		if err != nil {
+			return nil, err
+		}
+		f.before = before
+	}
+
+	var err error
+	if f.paths, err = compilePathGlobs(config.Paths); err != nil {
+		return nil, fmt.Errorf("invalid ignore path: %w", err)
+	}
+	if f.authorEmails, err = compileRegexes(config.AuthorEmails); err != nil {
+		return nil, fmt.Errorf("invalid ignore author-emails: %w", err)
+	}
+	if f.committerEmails, err = compileRegexes(config.CommitterEmails); err != nil {
+		return nil, fmt.Errorf("invalid ignore committer-emails: %w", err)
+	}
+	if f.messages, err = compileRegexes(config.Messages); err != nil {
+		return nil, fmt.Errorf("invalid ignore messages: %w", err)
+	}
+	return f, nil
+}
+
+func parseIgnoreDate(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid ignore commits-before date %q: expected RFC 3339 or YYYY-MM-DD", s)
+}
+
+func compileRegexes(patterns []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// ignores reports whether the commit matches any of the ignore rules. The path rule
+// is checked last because it is the only one that needs a diff.
+func (f *commitFilter) ignores(c *object.Commit, diffs commitDiffs) (bool, error) {
+	sha := c.Hash.String()
+	if f.fullSHAs[sha] {
+		return true, nil
+	}
+	for _, prefix := range f.shaPrefixes {
+		if strings.HasPrefix(sha, prefix) {
+			return true, nil
+		}
+	}
+	if !f.before.IsZero() && c.Committer.When.Before(f.before) {
+		return true, nil
+	}
+	if matchesAny(f.authorEmails, c.Author.Email) ||
+		matchesAny(f.committerEmails, c.Committer.Email) ||
+		matchesAny(f.messages, c.Message) {
+		return true, nil
+	}
+	if len(f.paths) == 0 {
+		return false, nil
+	}
+
+	paths, err := diffs.paths(c)
+	if err != nil {
+		return false, err
+	}
+	if len(paths) == 0 {
+		return false, nil
+	}
+	for _, p := range paths {
+		if !matchesAny(f.paths, p) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
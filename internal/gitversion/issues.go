@@ -0,0 +1,140 @@
+package gitversion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IssuePattern finds issue references in commit messages, trailers included.
+type IssuePattern struct {
+	// Pattern is a regex whose first capture group, or whole match if it has none,
+	// is the issue ID.
+	Pattern string `yaml:"pattern"`
+	// Text is how the reference is shown, with an {id} placeholder; "{id}" by default.
+	Text string `yaml:"text,omitempty"`
+	// URL links the reference, with an {id} placeholder. References without a URL are not linked.
+	URL string `yaml:"url,omitempty"`
+
+	// trailers, when set, restrict the pattern to the values of these trailers,
+	// by lower-case key.
+	trailers map[string]bool
+}
+
+// defaultIssuePatterns find GitHub-style #123 references anywhere, and Jira-style
+// ABC-123 keys in issue trailers only: in prose, UTF-8, SHA-256 or PEP-440 look
+// the same.
+var defaultIssuePatterns = []IssuePattern{
+	{Pattern: `(?:^|[^\w&])#(\d+)\b`, Text: "#{id}"},
+	{Pattern: `\b([A-Z][A-Z0-9]+-\d+)\b`, trailers: issueTrailers},
+}
+
+// issueTrailers are the trailers that reference issues, such as "Fixes: ABC-123".
+var issueTrailers = map[string]bool{
+	"closes": true, "fixes": true, "issue": true, "issues": true, "jira": true,
+	"ref": true, "refs": true, "references": true, "related": true, "relates-to": true,
+	"resolves": true, "see-also": true,
+}
+
+// IssueReference is an issue referenced by a commit.
+type IssueReference struct {
+	ID   string
+	Text string
+	URL  string
+}
+
+type compiledIssuePattern struct {
+	re      *regexp.Regexp
+	pattern IssuePattern
+}
+
+// issueMatcher is a list of issue patterns compiled once per calculation.
+type issueMatcher struct {
+	patterns []compiledIssuePattern
+}
+
+func newIssueMatcher(patterns []IssuePattern) (*issueMatcher, error) {
+	if len(patterns) == 0 {
+		patterns = defaultIssuePatterns
+	}
+	m := &issueMatcher{}
+	for i, p := range patterns {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid issues[%d] pattern: %w", i, err)
+		}
+		m.patterns = append(m.patterns, compiledIssuePattern{re: re, pattern: p})
+	}
+	return m, nil
+}
+
+// find returns the issues referenced in a commit message in order of appearance
+// per pattern, each one once.
+func (m *issueMatcher) find(message string) []IssueReference {
+	var refs []IssueReference
+	seen := make(map[string]bool)
+	trailers := ParseTrailers(message)
+	for _, p := range m.patterns {
+		for _, text := range p.searched(message, trailers) {
+			for _, match := range p.re.FindAllStringSubmatch(text, -1) {
+				id := match[0]
+				if len(match) > 1 {
+					id = match[1]
+				}
+				ref := p.reference(id)
+				if !seen[ref.Text] {
+					seen[ref.Text] = true
+					refs = append(refs, ref)
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// searched returns the parts of a commit message the pattern applies to: the
+// whole message, or the values of its trailers.
+func (p compiledIssuePattern) searched(message string, trailers []Trailer) []string {
+	if p.pattern.trailers == nil {
+		return []string{message}
+	}
+	var values []string
+	for _, t := range trailers {
+		if p.pattern.trailers[strings.ToLower(t.Key)] {
+			values = append(values, t.Value)
+		}
+	}
+	return values
+}
+
+func (p compiledIssuePattern) reference(id string) IssueReference {
+	text := p.pattern.Text
+	if text == "" {
+		text = "{id}"
+	}
+	ref := IssueReference{ID: id, Text: strings.ReplaceAll(text, "{id}", id)}
+	if p.pattern.URL != "" {
+		ref.URL = strings.ReplaceAll(p.pattern.URL, "{id}", id)
+	}
+	return ref
+}
+
+// findIssueReferences returns the issues referenced by the commits a calculation
+// analysed, oldest first and each one once.
+func findIssueReferences(ctx *VersionContext) ([]IssueReference, error) {
+	matcher, err := newIssueMatcher(ctx.Config.Issues)
+	if err != nil {
+		return nil, err
+	}
+	var refs []IssueReference
+	seen := make(map[string]bool)
+	for _, analysed := range ctx.Commits {
+		for _, ref := range matcher.find(analysed.Commit.Message) {
+			if !seen[ref.Text] {
+				seen[ref.Text] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,99 @@
+package gitversion
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of the settings of a layered configuration.
+const (
+	SourceDefault   = "default"
+	SourceFile      = "GitVersion.yml"
+	SourceInherited = "inherited"
+)
+
+// InheritValue makes a branch setting take the top-level value, as if it were unset.
+const InheritValue = "Inherit"
+
+// LoadConfig layers the content of a GitVersion.yml file over DefaultConfig:
+// top-level settings missing from the file keep their default. A branches section
+// in the file replaces the built-in branch patterns as a whole.
+func LoadConfig(data []byte) (*Config, error) {
+	var config Config
+	if err := yaml.Unmarshal([]byte(DefaultConfig), &config); err != nil {
+		return nil, fmt.Errorf("invalid default config: %w", err)
+	}
+	defaultKeys, err := topLevelKeys([]byte(DefaultConfig))
+	if err != nil {
+		return nil, fmt.Errorf("invalid default config: %w", err)
+	}
+	fileKeys, err := topLevelKeys(data)
+	if err != nil {
+		return nil, err
+	}
+
+	config.defaultBranches, config.Branches = config.Branches, nil
+	if fileKeys["branches"] {
+		config.defaultBranches = nil
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	config.sources = make(map[string]string)
+	for key := range defaultKeys {
+		config.sources[key] = SourceDefault
+	}
+	for key := range fileKeys {
+		config.sources[key] = SourceFile
+	}
+	return &config, nil
+}
+
+func topLevelKeys(data []byte) (map[string]bool, error) {
+	var doc map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(doc))
+	for key := range doc {
+		keys[key] = true
+	}
+	return keys, nil
+}
+
+// SettingSource returns where a top-level setting, by its YAML key, came from:
+// SourceFile, SourceDefault, or "" when it is not set.
+func (c *Config) SettingSource(key string) string {
+	return c.sources[key]
+}
+
+// BranchSource returns where a branches pattern came from.
+func (c *Config) BranchSource(pattern string) string {
+	if _, ok := c.Branches[pattern]; ok {
+		return SourceFile
+	}
+	if _, ok := c.defaultBranches[pattern]; ok {
+		return SourceDefault
+	}
+	return ""
+}
+
+// inherit fills in the branch settings that take the top-level value, and returns
+// their YAML keys.
+func (b *BranchConfig) inherit(c *Config) []string {
+	var inherited []string
+	if b.Increment == "" || strings.EqualFold(b.Increment, InheritValue) {
+		b.Increment = c.Increment
+		if b.Increment != "" {
+			inherited = append(inherited, "increment")
+		}
+	}
+	if len(b.Strategies) == 0 && len(c.Strategies) > 0 {
+		b.Strategies = c.Strategies
+		inherited = append(inherited, "strategies")
+	}
+	return inherited
+}
@@ -0,0 +1,127 @@
+package gitversion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// PathRule bounds the bump of commits that touch files matching Paths.
+type PathRule struct {
+	Paths []string `yaml:"paths"`
+	// Min raises the bump of any commit touching a matching file to at least this level.
+	Min string `yaml:"min,omitempty"`
+	// Max caps the bump of commits whose files all match a rule with a maximum.
+	Max string `yaml:"max,omitempty"`
+}
+
+// parseBump parses a bump level name as used in path rules.
+func parseBump(name string) (semverBump, error) {
+	switch strings.ToLower(name) {
+	case "none":
+		return noBump, nil
+	case "patch":
+		return patchBump, nil
+	case "minor":
+		return minorBump, nil
+	case "major":
+		return majorBump, nil
+	default:
+		return noBump, fmt.Errorf("invalid bump %q: expected none, patch, minor or major", name)
+	}
+}
+
+type compiledPathRule struct {
+	paths  []*regexp.Regexp
+	min    semverBump
+	hasMin bool
+	max    semverBump
+	hasMax bool
+}
+
+// pathRuleSet is a list of path rules compiled once per calculation.
+type pathRuleSet struct {
+	rules []compiledPathRule
+}
+
+func newPathRuleSet(rules []PathRule) (*pathRuleSet, error) {
+	set := &pathRuleSet{}
+	for i, rule := range rules {
+		compiled := compiledPathRule{}
+		var err error
+		if compiled.paths, err = compilePathGlobs(rule.Paths); err != nil {
+			return nil, fmt.Errorf("invalid path-rules[%d] path: %w", i, err)
+		}
+		if rule.Min != "" {
+			if compiled.min, err = parseBump(rule.Min); err != nil {
+				return nil, fmt.Errorf("invalid path-rules[%d] min: %w", i, err)
+			}
+			compiled.hasMin = true
+		}
+		if rule.Max != "" {
+			if compiled.max, err = parseBump(rule.Max); err != nil {
+				return nil, fmt.Errorf("invalid path-rules[%d] max: %w", i, err)
+			}
+			compiled.hasMax = true
+		}
+		set.rules = append(set.rules, compiled)
+	}
+	return set, nil
+}
+
+// apply bounds the bump of a commit by the files it touches. A maximum applies only
+// when every touched file falls under some maximum, and then the most permissive
+// of those maximums wins; minimums are applied afterwards, so they win over maximums.
+// The second result is false when a maximum of none suppressed the commit entirely,
+// so that it does not count as a change for the increment fallback either.
+func (s *pathRuleSet) apply(c *object.Commit, bump semverBump, diffs commitDiffs) (semverBump, bool, error) {
+	if len(s.rules) == 0 {
+		return bump, true, nil
+	}
+
+	paths, err := diffs.paths(c)
+	if err != nil {
+		return bump, true, err
+	}
+	if len(paths) == 0 {
+		return bump, true, nil
+	}
+
+	floor, hasFloor := noBump, false
+	ceiling, allCapped := noBump, true
+	for _, p := range paths {
+		fileCapped := false
+		fileCeiling := noBump
+		for _, rule := range s.rules {
+			if !matchesAny(rule.paths, p) {
+				continue
+			}
+			if rule.hasMin && (!hasFloor || rule.min > floor) {
+				floor, hasFloor = rule.min, true
+			}
+			if rule.hasMax && (!fileCapped || rule.max > fileCeiling) {
+				fileCeiling, fileCapped = rule.max, true
+			}
+		}
+		if !fileCapped {
+			allCapped = false
+		} else if fileCeiling > ceiling {
+			ceiling = fileCeiling
+		}
+	}
+
+	counts := true
+	if allCapped && bump > ceiling {
+		bump = ceiling
+	}
+	if allCapped && ceiling == noBump {
+		counts = false
+	}
+	if hasFloor && floor > bump {
+		bump = floor
+		counts = true
+	}
+	return bump, counts, nil
+}
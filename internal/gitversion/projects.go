@@ -0,0 +1,103 @@
+package gitversion
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ProjectConfig configures one project of a monorepo. Empty fields inherit the
+// top-level setting, except TagPrefix, which defaults to the regex "<name>/[vV]?".
+type ProjectConfig struct {
+	Path                    string     `yaml:"path"`
+	ExcludePaths            []string   `yaml:"exclude-paths,omitempty"`
+	TagPrefix               string     `yaml:"tag-prefix,omitempty"`
+	NextVersion             string     `yaml:"next-version,omitempty"`
+	Increment               string     `yaml:"increment,omitempty"`
+	MajorVersionBumpMessage string     `yaml:"major-version-bump-message,omitempty"`
+	MinorVersionBumpMessage string     `yaml:"minor-version-bump-message,omitempty"`
+	PatchVersionBumpMessage string     `yaml:"patch-version-bump-message,omitempty"`
+	NoBumpMessage           string     `yaml:"no-bump-message,omitempty"`
+	PathRules               []PathRule `yaml:"path-rules,omitempty"`
+
+	goModule GoModule // set for projects discovered in go-modules mode
+}
+
+// ProjectNames returns the names of the configured projects in sorted order.
+func (c *Config) ProjectNames() []string {
+	names := make([]string, 0, len(c.Projects))
+	for name := range c.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForProject returns a copy of the configuration with the settings of the named
+// project applied on top, for use with the regular strategy pipeline.
+func (c *Config) ForProject(name string) (*Config, error) {
+	project, ok := c.Projects[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown project: %s", name)
+	}
+
+	projectConfig := *c
+	projectConfig.Projects = nil
+	projectConfig.TagPrefix = project.TagPrefix
+	if projectConfig.TagPrefix == "" {
+		projectConfig.TagPrefix = regexp.QuoteMeta(name) + "/[vV]?"
+	}
+	overrideString(&projectConfig.NextVersion, project.NextVersion)
+	overrideString(&projectConfig.Increment, project.Increment)
+	overrideString(&projectConfig.MajorVersionBumpMessage, project.MajorVersionBumpMessage)
+	overrideString(&projectConfig.MinorVersionBumpMessage, project.MinorVersionBumpMessage)
+	overrideString(&projectConfig.PatchVersionBumpMessage, project.PatchVersionBumpMessage)
+	overrideString(&projectConfig.NoBumpMessage, project.NoBumpMessage)
+	if len(project.PathRules) > 0 {
+		projectConfig.PathRules = project.PathRules
+	}
+	projectConfig.goModule = project.goModule
+	return &projectConfig, nil
+}
+
+func overrideString(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
+}
+
+// cleanProjectPath normalises a project path to a slash-separated path relative to
+// the repository root, with "" standing for the whole repository.
+func cleanProjectPath(p string) string {
+	p = path.Clean(strings.Trim(strings.ReplaceAll(p, "\\", "/"), "/"))
+	if p == "." {
+		return ""
+	}
+	return p
+}
+
+// touchesPath reports whether any of the paths is inside the project directory
+// and outside all of the excluded directories.
+func touchesPath(paths []string, projectPath string, excludes ...string) bool {
+	for _, p := range paths {
+		if isInDir(p, projectPath) && !isInAnyDir(p, excludes) {
+			return true
+		}
+	}
+	return false
+}
+
+func isInDir(p, dir string) bool {
+	return dir == "" || p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+func isInAnyDir(p string, dirs []string) bool {
+	for _, dir := range dirs {
+		if isInDir(p, cleanProjectPath(dir)) {
+			return true
+		}
+	}
+	return false
+}
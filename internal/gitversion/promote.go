@@ -0,0 +1,127 @@
+package gitversion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Promotion is a pre-release to be released as its final version on the same commit.
+type Promotion struct {
+	// Context covers the commits from the previous final release up to the
+	// pre-release, across all pre-releases in between; its NextVersion is the final
+	// version and its HeadCommit the pre-release's commit.
+	Context       *VersionContext
+	PreRelease    *semver.Version
+	PreReleaseTag string
+	// FinalTag is the name of the tag to create, named like the pre-release tag.
+	FinalTag string
+}
+
+// CalculatePromotion prepares promoting the tagged pre-release version, of the
+// repository or of a project when projectName is set. It fails when the final
+// version has already been tagged or when a higher pre-release of the same
+// version exists.
+func CalculatePromotion(r *git.Repository, config *Config, currentBranchName, projectName, version string) (*Promotion, error) {
+	pre, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %q: %w", version, err)
+	}
+	if pre.Prerelease() == "" {
+		return nil, fmt.Errorf("%s is not a pre-release", pre)
+	}
+	final := semver.New(pre.Major(), pre.Minor(), pre.Patch(), "", "")
+
+	ctx := &VersionContext{Repository: r, Config: config, CurrentBranchName: currentBranchName}
+	if projectName != "" {
+		if ctx, err = newProjectContext(r, config, currentBranchName, projectName); err != nil {
+			return nil, err
+		}
+	}
+	// Yanked, retracted and untrusted tags count as missing, like in a calculation.
+	if ctx.Config, err = withSkippedVersions(r, ctx.Config, ctx.ProjectPath); err != nil {
+		return nil, err
+	}
+	if ctx.Config, err = withTrustedTags(ctx); err != nil {
+		return nil, err
+	}
+	for _, v := range []*semver.Version{pre, final} {
+		if ctx.Config.skipped.skips(v) {
+			return nil, fmt.Errorf("version %s is retracted or yanked", v)
+		}
+	}
+	tags, err := ctx.tagIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	promotion := &Promotion{PreRelease: pre}
+	var preOriginal string
+	var preCommit *object.Commit
+	var untrustedPre string
+	var previous []tagCandidate
+	var newer []*semver.Version
+	for _, tag := range tags.Tags {
+		v, ok := tagVersion(ctx.Config, tag.Name)
+		if !ok {
+			continue
+		}
+		switch {
+		case v.Equal(pre):
+			if !ctx.Config.trusts(tag.Name) {
+				untrustedPre = tag.Name
+				continue
+			}
+			promotion.PreReleaseTag, preOriginal, preCommit = tag.Name, v.Original(), tag.Commit
+		case v.Equal(final):
+			return nil, fmt.Errorf("version %s already exists as tag %s", final, tag.Name)
+		case v.Prerelease() != "" && sameCoreVersion(v, final) && v.GreaterThan(pre):
+			if ctx.Config.trusts(tag.Name) {
+				newer = append(newer, v)
+			}
+		case v.Prerelease() == "" && v.LessThan(final):
+			previous = append(previous, tagCandidate{tag.Name, v, tag.Commit, 0})
+		}
+	}
+	if preCommit == nil && untrustedPre != "" {
+		return nil, fmt.Errorf("tag %s of version %s has no trusted signature", untrustedPre, pre)
+	}
+	if preCommit == nil {
+		return nil, fmt.Errorf("no tag found for version %s", pre)
+	}
+	if len(newer) > 0 {
+		sort.Sort(semver.Collection(newer))
+		return nil, fmt.Errorf("%s is not the highest pre-release of %s: %s is newer", pre, final, newer[len(newer)-1])
+	}
+
+	at := strings.LastIndex(promotion.PreReleaseTag, preOriginal)
+	promotion.FinalTag = promotion.PreReleaseTag[:at] + final.String() + promotion.PreReleaseTag[at+len(preOriginal):]
+	if v, ok := tagVersion(ctx.Config, promotion.FinalTag); !ok || !v.Equal(final) {
+		return nil, fmt.Errorf("tag %s does not match tag-prefix as version %s", promotion.FinalTag, final)
+	}
+
+	// The notes of the final version gather the commits of all its pre-releases;
+	// like any first release, one without a previous release has none.
+	ctx.HeadCommit = preCommit
+	if previous := latestTrusted(ctx.Config, previous, nil); previous != nil {
+		ctx.BaseVersion, ctx.BaseVersionCommit = previous.version, previous.commit
+		if ctx, err = calculate(ctx); err != nil {
+			return nil, err
+		}
+	}
+	ctx.Warnings = append(ctx.Warnings, ctx.Config.untrustedTagWarnings()...)
+	// The final version is released as is, without a branch label.
+	ctx.NextVersion = final
+	ctx.CommitsSinceLastTag = 0
+	ctx.SemverLabel = nil
+	promotion.Context = ctx
+	return promotion, nil
+}
+
+func sameCoreVersion(a, b *semver.Version) bool {
+	return a.Major() == b.Major() && a.Minor() == b.Minor() && a.Patch() == b.Patch()
+}
@@ -0,0 +1,62 @@
+package gitversion
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// LDFlagsConfig names the package variables the go output format sets with -X.
+type LDFlagsConfig struct {
+	// Version defaults to main.Version.
+	Version string `yaml:"version,omitempty"`
+	// Commit defaults to main.Commit.
+	Commit string `yaml:"commit,omitempty"`
+}
+
+// VersionVar returns the configured version variable or its default.
+func (c LDFlagsConfig) VersionVar() string {
+	if c.Version == "" {
+		return "main.Version"
+	}
+	return c.Version
+}
+
+// CommitVar returns the configured commit variable or its default.
+func (c LDFlagsConfig) CommitVar() string {
+	if c.Commit == "" {
+		return "main.Commit"
+	}
+	return c.Commit
+}
+
+// PseudoVersion returns the Go module pseudo-version the go command would give
+// HEAD, such as v1.2.4-0.20261017120000-abcdef123456, built from the base version,
+// the commit time in UTC and the first 12 characters of the SHA. When HEAD carries
+// the base version's tag, that version is returned as is.
+func PseudoVersion(ctx *VersionContext) (string, error) {
+	commit := ctx.HeadCommit
+	if commit == nil {
+		return "", errors.New("repository has no HEAD commit")
+	}
+
+	older := ""
+	if ctx.BaseVersion != nil {
+		base, err := ctx.BaseVersion.SetMetadata("")
+		if err != nil {
+			return "", err
+		}
+		older = "v" + base.String()
+		if ctx.BaseVersionCommit != nil && ctx.BaseVersionCommit.Hash == commit.Hash {
+			return older, nil
+		}
+	}
+
+	major := ""
+	if _, pathMajor, ok := module.SplitPathVersion(ctx.Config.goModule.Path); ok {
+		major = module.PathMajorPrefix(pathMajor)
+	}
+	rev := strings.ToLower(commit.Hash.String())[:12]
+	return module.PseudoVersion(major, older, commit.Committer.When.UTC(), rev), nil
+}
@@ -0,0 +1,127 @@
+package gitversion
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"golang.org/x/mod/modfile"
+	modsemver "golang.org/x/mod/semver"
+)
+
+// skippedVersions are versions that must never be chosen as the base version or
+// produced as the next version: those retracted in go.mod and those configured
+// as yanked.
+type skippedVersions struct {
+	retracted []modfile.VersionInterval
+	yanked    []*semver.Version
+}
+
+// withSkippedVersions returns a copy of the configuration that knows the retract
+// directives of the go.mod at HEAD in the module directory and the yanked versions.
+func withSkippedVersions(r *git.Repository, config *Config, dir string) (*Config, error) {
+	skipped := &skippedVersions{}
+	for _, y := range config.Yanked {
+		v, err := semver.NewVersion(y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid yanked version %q: %w", y, err)
+		}
+		skipped.yanked = append(skipped.yanked, v)
+	}
+
+	head, err := r.Head()
+	if err != nil && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, err
+	}
+	if head != nil {
+		commit, err := r.CommitObject(head.Hash())
+		if err != nil {
+			return nil, err
+		}
+		if config.goModule.Path != "" {
+			dir = config.goModule.Dir
+		}
+		modFile, err := readGoMod(commit, dir)
+		if err != nil {
+			return nil, err
+		}
+		if modFile != nil {
+			for _, retract := range modFile.Retract {
+				skipped.retracted = append(skipped.retracted, retract.VersionInterval)
+			}
+		}
+	}
+
+	withSkipped := *config
+	withSkipped.skipped = skipped
+	return &withSkipped, nil
+}
+
+// skips reports whether v is retracted or yanked.
+func (s *skippedVersions) skips(v *semver.Version) bool {
+	if s == nil {
+		return false
+	}
+	for _, y := range s.yanked {
+		if v.Equal(y) {
+			return true
+		}
+	}
+	goVersion := "v" + v.String()
+	for _, interval := range s.retracted {
+		if modsemver.Compare(interval.Low, goVersion) <= 0 && modsemver.Compare(goVersion, interval.High) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// retractedUpTo returns the highest upper bound of the retract intervals holding
+// v, or nil when none does.
+func (s *skippedVersions) retractedUpTo(v *semver.Version) *semver.Version {
+	if s == nil {
+		return nil
+	}
+	var high *semver.Version
+	goVersion := "v" + v.String()
+	for _, interval := range s.retracted {
+		if modsemver.Compare(interval.Low, goVersion) > 0 || modsemver.Compare(goVersion, interval.High) > 0 {
+			continue
+		}
+		h, err := semver.NewVersion(interval.High)
+		if err == nil && (high == nil || h.GreaterThan(high)) {
+			high = h
+		}
+	}
+	return high
+}
+
+// skipPastSkippedVersions moves a new next version past retracted and yanked
+// versions by repeating its bump, e.g. from a retracted 1.4.0 to 1.5.0. A retracted
+// range is skipped in one step by bumping its upper bound, e.g. to 1.1000.0 for
+// retract [v1.4.0, v1.999.0].
+func skipPastSkippedVersions(ctx *VersionContext) {
+	if !ctx.Changed() || !ctx.Config.skipped.skips(ctx.NextVersion) {
+		return
+	}
+	skipped := ctx.NextVersion
+	next := *ctx.NextVersion
+	for ctx.Config.skipped.skips(&next) {
+		from := next
+		if high := ctx.Config.skipped.retractedUpTo(&next); high != nil {
+			from = *high
+		}
+		switch ctx.Bump {
+		case majorBump:
+			next = from.IncMajor()
+		case minorBump:
+			next = from.IncMinor()
+		default:
+			next = from.IncPatch()
+		}
+	}
+	ctx.NextVersion = &next
+	ctx.Warnings = append(ctx.Warnings, fmt.Sprintf("version %s is retracted or yanked; using %s instead", skipped, &next))
+}
@@ -0,0 +1,49 @@
+package gitversion
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var revertsCommitRegex = regexp.MustCompile(`This reverts commit ([0-9a-fA-F]{7,40})`)
+
+// RevertPair is a revert commit together with the commit it undoes.
+type RevertPair struct {
+	Revert   *object.Commit
+	Reverted *object.Commit
+}
+
+// findRevertPairs pairs revert commits with the commits they undo. Both sides of a
+// pair must be in commits, which must be ordered with the oldest commit first.
+// Walking back from the newest commit means a revert of a revert cancels the first
+// revert and leaves the original change in place.
+func findRevertPairs(commits []*object.Commit) ([]RevertPair, map[plumbing.Hash]bool) {
+	var pairs []RevertPair
+	cancelled := make(map[plumbing.Hash]bool)
+
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		if cancelled[c.Hash] {
+			continue
+		}
+		matches := revertsCommitRegex.FindStringSubmatch(c.Message)
+		if matches == nil {
+			continue
+		}
+		target := strings.ToLower(matches[1])
+		for j := i - 1; j >= 0; j-- {
+			older := commits[j]
+			if cancelled[older.Hash] || !strings.HasPrefix(older.Hash.String(), target) {
+				continue
+			}
+			cancelled[c.Hash] = true
+			cancelled[older.Hash] = true
+			pairs = append(pairs, RevertPair{Revert: c, Reverted: older})
+			break
+		}
+	}
+	return pairs, cancelled
+}
@@ -0,0 +1,304 @@
+package gitversion
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// SetTrustedSSHKeys sets the allowed signers file, in the format of ssh-keygen,
+// against which SSH tag signatures are checked when require-signed-tags is set.
+func (c *Config) SetTrustedSSHKeys(file string) {
+	c.trustedSSHKeys = file
+}
+
+// SetTrustedKeys parses an armored OpenPGP public keyring, the content of the
+// trusted-keys file, against which tag signatures are checked when
+// require-signed-tags is set.
+func (c *Config) SetTrustedKeys(armored []byte) error {
+	keyRing, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armored))
+	if err != nil {
+		return fmt.Errorf("invalid trusted keys: %w", err)
+	}
+	c.trustedKeys = keyRing
+	return nil
+}
+
+// Formats of tag.signing-format.
+const (
+	// SigningFormatOpenPGP signs with an armored OpenPGP private key read from a file.
+	SigningFormatOpenPGP = "openpgp"
+	// SigningFormatGPG signs with the gpg program, which gets the key, named by its
+	// ID, fingerprint or user ID, from gpg-agent.
+	SigningFormatGPG = "gpg"
+	// SigningFormatSSH signs with ssh-keygen, given a private key file, or a public
+	// key file whose private key ssh-agent holds.
+	SigningFormatSSH = "ssh"
+)
+
+// ReadSigningKey parses an armored OpenPGP private key for signing tags. An
+// encrypted key is decrypted with the passphrase.
+func ReadSigningKey(armored []byte, passphrase string) (*openpgp.Entity, error) {
+	keyRing, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armored))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	if len(keyRing) == 0 || keyRing[0].PrivateKey == nil {
+		return nil, errors.New("invalid signing key: no private key found")
+	}
+	key := keyRing[0]
+	if key.PrivateKey.Encrypted {
+		if passphrase == "" {
+			return nil, errors.New("signing key is encrypted and no passphrase was given")
+		}
+		if err := key.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt signing key: %w", err)
+		}
+	}
+	return key, nil
+}
+
+// NewOpenPGPSigner signs with an OpenPGP private key that has been decrypted.
+func NewOpenPGPSigner(key *openpgp.Entity) git.Signer {
+	return openpgpSigner{key}
+}
+
+type openpgpSigner struct {
+	key *openpgp.Entity
+}
+
+func (s openpgpSigner) Sign(message io.Reader) ([]byte, error) {
+	var signature bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&signature, s.key, message, nil); err != nil {
+		return nil, err
+	}
+	return signature.Bytes(), nil
+}
+
+// NewGPGSigner signs with the gpg program and the key it has for keyID.
+func NewGPGSigner(keyID string) git.Signer {
+	return programSigner{"gpg", []string{"--detach-sign", "--armor", "--local-user", keyID}}
+}
+
+// NewSSHSigner signs with ssh-keygen and keyFile, as Git does with gpg.format ssh.
+func NewSSHSigner(keyFile string) git.Signer {
+	return programSigner{"ssh-keygen", []string{"-Y", "sign", "-n", "git", "-f", keyFile}}
+}
+
+// programSigner runs a program that reads the message on stdin and writes the
+// armored signature to stdout.
+type programSigner struct {
+	name string
+	args []string
+}
+
+func (s programSigner) Sign(message io.Reader) ([]byte, error) {
+	cmd := exec.Command(s.name, s.args...)
+	cmd.Stdin = message
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", s.name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// CreateSignedTag tags hash with an annotated tag signed by signer. go-git only
+// signs tags with OpenPGP keys it holds itself, so the tag object is built here.
+func CreateSignedTag(r *git.Repository, name string, hash plumbing.Hash, message string, signer git.Signer) (*plumbing.Reference, error) {
+	refName := plumbing.NewTagReferenceName(name)
+	if err := refName.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := r.Storer.Reference(refName); err == nil {
+		return nil, git.ErrTagExists
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, err
+	}
+	opts := &git.CreateTagOptions{Message: message}
+	if err := opts.Validate(r, hash); err != nil {
+		return nil, err
+	}
+
+	tag := &object.Tag{Name: name, Tagger: *opts.Tagger, Message: opts.Message, TargetType: plumbing.CommitObject, Target: hash}
+	unsigned := &plumbing.MemoryObject{}
+	if err := tag.EncodeWithoutSignature(unsigned); err != nil {
+		return nil, err
+	}
+	content, err := unsigned.Reader()
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign tag: %w", err)
+	}
+	tag.PGPSignature = string(signature)
+
+	obj := r.Storer.NewEncodedObject()
+	if err := tag.Encode(obj); err != nil {
+		return nil, err
+	}
+	tagHash, err := r.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	ref := plumbing.NewHashReference(refName, tagHash)
+	return ref, r.Storer.SetReference(ref)
+}
+
+// withTrustedTags returns a copy of the configuration that rejects version tags
+// without a valid signature by one of the trusted keys, when require-signed-tags
+// is set. Signatures are verified when a tag is about to be used; see trusts.
+func withTrustedTags(ctx *VersionContext) (*Config, error) {
+	config := ctx.Config
+	if !config.RequireSignedTags || config.trust != nil {
+		return config, nil
+	}
+	if len(config.trustedKeys) == 0 && config.trustedSSHKeys == "" {
+		return nil, errors.New("require-signed-tags needs trusted-keys or trusted-ssh-keys")
+	}
+
+	tags, err := ctx.tagIndex()
+	if err != nil {
+		return nil, err
+	}
+	trust := &tagTrust{
+		repo:    ctx.Repository,
+		keys:    config.trustedKeys,
+		sshKeys: config.trustedSSHKeys,
+		refs:    make(map[string]*plumbing.Reference),
+		trusted: make(map[string]bool),
+	}
+	for _, tag := range tags.Tags {
+		trust.refs[tag.Name] = tag.Ref
+	}
+	withTrusted := *config
+	withTrusted.trust = trust
+	return &withTrusted, nil
+}
+
+// tagTrust verifies tag signatures for require-signed-tags, each tag once.
+type tagTrust struct {
+	repo     *git.Repository
+	keys     openpgp.EntityList
+	sshKeys  string
+	refs     map[string]*plumbing.Reference
+	trusted  map[string]bool // verified tags by name
+	warnings []string
+}
+
+// verify checks the signature of an annotated tag against the trusted keys of
+// its kind.
+func (t *tagTrust) verify(ref *plumbing.Reference) error {
+	tag, err := t.repo.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return errors.New("lightweight tags cannot be signed")
+	}
+	if err != nil {
+		return err
+	}
+	if tag.PGPSignature == "" {
+		return errors.New("tag is not signed")
+	}
+
+	encoded := &plumbing.MemoryObject{}
+	if err := tag.EncodeWithoutSignature(encoded); err != nil {
+		return err
+	}
+	signed, err := encoded.Reader()
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(tag.PGPSignature, "-----BEGIN SSH SIGNATURE-----") {
+		if t.sshKeys == "" {
+			return errors.New("SSH signatures need trusted-ssh-keys")
+		}
+		return verifySSHSignature(t.sshKeys, signed, tag.PGPSignature)
+	}
+	if len(t.keys) == 0 {
+		return errors.New("OpenPGP signatures need trusted-keys")
+	}
+	if _, err := openpgp.CheckArmoredDetachedSignature(t.keys, signed, strings.NewReader(tag.PGPSignature), nil); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	return nil
+}
+
+// verifySSHSignature checks an SSH signature with ssh-keygen against an allowed
+// signers file, for the principal the file lists for the signing key.
+func verifySSHSignature(allowedSigners string, message io.Reader, signature string) error {
+	sigFile, err := os.CreateTemp("", "gitversion-*.sig")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(sigFile.Name())
+	if _, err := sigFile.WriteString(signature); err != nil {
+		sigFile.Close()
+		return err
+	}
+	if err := sigFile.Close(); err != nil {
+		return err
+	}
+
+	var stdout, stderr bytes.Buffer
+	find := exec.Command("ssh-keygen", "-Y", "find-principals", "-f", allowedSigners, "-s", sigFile.Name())
+	find.Stdout, find.Stderr = &stdout, &stderr
+	if err := find.Run(); err != nil {
+		return fmt.Errorf("invalid signature: no trusted SSH key: %s", strings.TrimSpace(stderr.String()))
+	}
+	principal, _, _ := strings.Cut(strings.TrimSpace(stdout.String()), "\n")
+
+	stderr.Reset()
+	verify := exec.Command("ssh-keygen", "-Y", "verify", "-f", allowedSigners, "-I", principal, "-n", "git", "-s", sigFile.Name())
+	verify.Stdin, verify.Stderr = message, &stderr
+	if err := verify.Run(); err != nil {
+		return fmt.Errorf("invalid signature: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// trusts reports whether a tag may be used as a version; see withTrustedTags.
+// The signature of a tag is verified on the first call for it, and a rejected
+// tag is reported by untrustedTagWarnings.
+func (c *Config) trusts(tagName string) bool {
+	t := c.trust
+	if t == nil {
+		return true
+	}
+	if trusted, ok := t.trusted[tagName]; ok {
+		return trusted
+	}
+	ref, ok := t.refs[tagName]
+	if !ok {
+		return false
+	}
+	err := t.verify(ref)
+	if err != nil {
+		t.warnings = append(t.warnings, fmt.Sprintf("ignoring tag %s: %v", tagName, err))
+	}
+	t.trusted[tagName] = err == nil
+	return err == nil
+}
+
+// untrustedTagWarnings returns a warning for every tag trusts rejected since the
+// last call, in order of tag name.
+func (c *Config) untrustedTagWarnings() []string {
+	if c.trust == nil {
+		return nil
+	}
+	warnings := c.trust.warnings
+	c.trust.warnings = nil
+	sort.Strings(warnings)
+	return warnings
+}
@@ -1,12 +1,14 @@
 package gitversion
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/storer"
 )
@@ -16,13 +18,34 @@ type VersionContext struct {
 	Repository           *git.Repository
 	Config               *Config
 	CurrentBranchName    string
+	ProjectName          string    // set when calculating a single monorepo project
+	ProjectPath          string    // only commits touching this directory are considered
+	ProjectExcludes      []string  // subdirectories of ProjectPath that do not belong to the project
+	Tags                 *TagIndex // shared tag index; built on first use when nil
+	BumpedBy             []string  // projects whose changes were passed on through go.mod dependencies
+	Warnings             []string  // problems worth reporting that do not stop the calculation
 	BaseVersion          *semver.Version
 	BaseVersionCommit    *object.Commit
 	NextVersion          *semver.Version
 	Bump                 semverBump
 	CommitsSinceLastTag  int
-	FormattedCommitDates []string // commit dates formatted per config.CommitDateFormat
-	MergeCommitIndices   []int    // indices in commits slice that match merge-message-formats
+	FormattedCommitDates []string         // commit dates formatted per config.CommitDateFormat
+	MergeCommitIndices   []int            // indices in commits slice that match merge-message-formats
+	ReleaseAs            *TrailerOverride // set by a Release-As trailer since the base version
+	SemverLabel          *TrailerOverride // set by a Semver-Label trailer since the base version
+	CancelledReverts     []RevertPair     // commits left out of bump analysis because they were reverted
+	CherryPicks          []CherryPickPair // cherry-picked copies counted only once
+	APIDiff              *APIDiff         // set by the api-diff strategy
+	HeadCommit           *object.Commit   // the commit versioned; HEAD unless set before the calculation
+	Commits              []AnalysedCommit // commits since the base version that count, oldest first
+	Issues               []IssueReference // issues referenced by Commits, for release tracking
+	Trace                *Trace           // decisions made by the calculation; see calculate --explain
+}
+
+// AnalysedCommit is a commit together with the bump it contributes after path rules.
+type AnalysedCommit struct {
+	Commit *object.Commit
+	Bump   semverBump
 }
 
 type semverBump int
@@ -34,6 +57,10 @@ const (
 	majorBump
 )
 
+func (b semverBump) String() string {
+	return [...]string{"none", "patch", "minor", "major"}[b]
+}
+
 // VersioningStrategy defines the interface for a versioning strategy.
 type VersioningStrategy interface {
 	Execute(ctx *VersionContext) (bool, error)
@@ -49,15 +76,36 @@ func NewStrategyExecutor(strategies []VersioningStrategy) *StrategyExecutor {
 	return &StrategyExecutor{Strategies: strategies}
 }
 
-// ExecuteStrategies runs the strategies in order until one of them succeeds.
+// ExecuteStrategies runs the strategies in order until one of them succeeds,
+// recording each one in ctx.Trace when it is set.
 func (e *StrategyExecutor) ExecuteStrategies(ctx *VersionContext) error {
+	producer := ""
 	for _, strategy := range e.Strategies {
+		name := strategyName(strategy)
+		if producer != "" {
+			if ctx.Trace != nil {
+				ctx.Trace.Strategies = append(ctx.Trace.Strategies, StrategyTrace{
+					Name:    name,
+					Outcome: fmt.Sprintf("skipped: %s already produced the version", producer),
+				})
+			}
+			continue
+		}
+
+		hadBaseVersion, hadAPIDiff := ctx.BaseVersion != nil, ctx.APIDiff != nil
 		success, err := strategy.Execute(ctx)
 		if err != nil {
 			return err
 		}
+		if ctx.Trace != nil {
+			ctx.Trace.Strategies = append(ctx.Trace.Strategies, StrategyTrace{
+				Name:    name,
+				Ran:     true,
+				Outcome: strategyOutcome(ctx, success, hadBaseVersion, hadAPIDiff),
+			})
+		}
 		if success {
-			break
+			producer = name
 		}
 	}
 	return nil
@@ -72,7 +120,15 @@ func (s *FindLatestTagStrategy) Execute(ctx *VersionContext) (bool, error) {
 		return false, nil
 	}
 
-	latestVersion, latestTagCommit, err := FindLatestVersion(ctx.Repository, ctx.Config, ctx.CurrentBranchName)
+	if ctx.Tags == nil {
+		tags, err := NewTagIndex(ctx.Repository)
+		if err != nil {
+			return false, err
+		}
+		ctx.Tags = tags
+	}
+
+	latestVersion, latestTagCommit, err := findLatestVersion(ctx.Repository, ctx.Config, ctx.CurrentBranchName, ctx.Tags, ctx.Trace)
 	if err != nil {
 		return false, err
 	}
@@ -85,45 +141,75 @@ func (s *FindLatestTagStrategy) Execute(ctx *VersionContext) (bool, error) {
 	return false, nil
 }
 
+var conventionalCommitRegex = regexp.MustCompile(`^(feat|fix|build|chore|ci|docs|perf|refactor|revert|style|test)(\(.*\))?(!?):`)
+
+// ConventionalCommit is the parsed header of a commit message. Type is empty when
+// the header does not follow Conventional Commits, and Description is then the
+// whole header.
+type ConventionalCommit struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+// ParseConventionalCommit parses the header of a commit message.
+func ParseConventionalCommit(message string) ConventionalCommit {
+	header := strings.Split(message, "\n")[0]
+	parsed := ConventionalCommit{
+		Breaking:    strings.Contains(message, "BREAKING CHANGE:"),
+		Description: strings.TrimSpace(header),
+	}
+	if matches := conventionalCommitRegex.FindStringSubmatch(header); matches != nil {
+		parsed.Type = matches[1]
+		parsed.Scope = strings.TrimSuffix(strings.TrimPrefix(matches[2], "("), ")")
+		parsed.Breaking = parsed.Breaking || matches[3] == "!"
+		parsed.Description = strings.TrimSpace(header[len(matches[0]):])
+	}
+	return parsed
+}
+
 // getBumpFromMessage analyzes a commit message and returns the bump type.
 func getBumpFromMessage(config *Config, message string) semverBump {
-	// Conventional commits
-	commitLines := strings.Split(message, "\n")
-	header := commitLines[0]
-	conventionalCommitRegex := regexp.MustCompile(`^(feat|fix|build|chore|ci|docs|perf|refactor|revert|style|test)(\(.*\))?(!?):`)
-	matches := conventionalCommitRegex.FindStringSubmatch(header)
-	isBreaking := strings.Contains(message, "BREAKING CHANGE:") || (len(matches) > 3 && matches[3] == "!")
+	bump, _ := classifyMessage(config, message)
+	return bump
+}
 
-	if isBreaking {
-		return majorBump
-	}
-	if len(matches) > 1 {
-		switch matches[1] {
-		case "feat":
-			return minorBump
-		case "fix":
-			return patchBump
+// classifyMessage returns the bump of a commit message and the rule that decided it.
+func classifyMessage(config *Config, message string) (semverBump, string) {
+	// Conventional commits
+	parsed := ParseConventionalCommit(message)
+	if parsed.Breaking {
+		if strings.Contains(message, "BREAKING CHANGE:") {
+			return majorBump, "BREAKING CHANGE footer"
 		}
+		return majorBump, "breaking change marker !"
+	}
+	switch parsed.Type {
+	case "feat":
+		return minorBump, "conventional commit type feat"
+	case "fix":
+		return patchBump, "conventional commit type fix"
 	}
 
 	// Custom regexes
 	if config.MajorVersionBumpMessage != "" {
 		if matched, _ := regexp.MatchString(config.MajorVersionBumpMessage, message); matched {
-			return majorBump
+			return majorBump, "major-version-bump-message"
 		}
 	}
 	if config.MinorVersionBumpMessage != "" {
 		if matched, _ := regexp.MatchString(config.MinorVersionBumpMessage, message); matched {
-			return minorBump
+			return minorBump, "minor-version-bump-message"
 		}
 	}
 	if config.PatchVersionBumpMessage != "" {
 		if matched, _ := regexp.MatchString(config.PatchVersionBumpMessage, message); matched {
-			return patchBump
+			return patchBump, "patch-version-bump-message"
 		}
 	}
 
-	return noBump
+	return noBump, "no bump rule matched"
 }
 
 // IncrementFromCommitsStrategy increments the base version based on commit messages.
@@ -132,10 +218,7 @@ type IncrementFromCommitsStrategy struct{}
 // Execute runs the IncrementFromCommitsStrategy to determine the next version from commit messages.
 func (s *IncrementFromCommitsStrategy) Execute(ctx *VersionContext) (bool, error) {
 	// Determine commit date format
-	commitDateFormat := ctx.Config.CommitDateFormat
-	if commitDateFormat == "" {
-		commitDateFormat = "2006-01-02T15:04:05Z07:00" // ISO8601 default
-	}
+	commitDateFormat := ctx.Config.CommitDateLayout()
 	// Prepare merge commit regexes
 	var mergeRegexes []*regexp.Regexp
 	if len(ctx.Config.MergeMessageFormats) > 0 {
@@ -158,39 +241,58 @@ func (s *IncrementFromCommitsStrategy) Execute(ctx *VersionContext) (bool, error
 		}
 	}
 
-	if ctx.BaseVersion == nil || ctx.NextVersion != nil {
+	if ctx.NextVersion != nil {
 		return false, nil
 	}
 
-	head, err := ctx.Repository.Head()
+	ignoreFilter, err := newCommitFilter(ctx.Config.Ignore)
 	if err != nil {
 		return false, err
 	}
 
-	commitIter, err := ctx.Repository.Log(&git.LogOptions{From: head.Hash()})
+	commitIter, err := commitsSinceBase(ctx)
 	if err != nil {
 		return false, err
 	}
 	defer commitIter.Close()
 
 	var commits []*object.Commit
+	diffs := make(commitDiffs)
+	walked := make(map[plumbing.Hash]bool)
+	var traced []CommitTrace // newest first, like the walk
+	traceCommit := func(c *object.Commit, skipped string) {
+		if ctx.Trace != nil {
+			entry := newCommitTrace(c)
+			entry.Skipped = skipped
+			traced = append(traced, entry)
+		}
+	}
 	ctx.FormattedCommitDates = nil
 	ctx.MergeCommitIndices = nil
 	err = commitIter.ForEach(func(c *object.Commit) error {
 		if ctx.BaseVersionCommit != nil && c.Hash == ctx.BaseVersionCommit.Hash {
 			return storer.ErrStop
 		}
-		// Ignore commit if SHA is in config.Ignore
-		ignored := false
-		for _, sha := range ctx.Config.Ignore {
-			if c.Hash.String() == sha {
-				ignored = true
-				break
-			}
+		walked[c.Hash] = true
+		ignored, err := ignoreFilter.ignores(c, diffs)
+		if err != nil {
+			return err
 		}
 		if ignored {
+			traceCommit(c, "ignored by the ignore config")
 			return nil
 		}
+		if ctx.ProjectPath != "" || len(ctx.ProjectExcludes) > 0 {
+			paths, err := diffs.paths(c)
+			if err != nil {
+				return err
+			}
+			if !touchesPath(paths, ctx.ProjectPath, ctx.ProjectExcludes...) {
+				traceCommit(c, "does not touch the project path")
+				return nil
+			}
+		}
+		traceCommit(c, "")
 		commits = append(commits, c)
 		// Format and store commit date
 		ctx.FormattedCommitDates = append(ctx.FormattedCommitDates, c.Committer.When.Format(commitDateFormat))
@@ -206,17 +308,123 @@ func (s *IncrementFromCommitsStrategy) Execute(ctx *VersionContext) (bool, error
 	if err != nil && err != storer.ErrStop {
 		return false, err
 	}
-	// Reverse commits so that commits[0] is the most recent
+	// Reverse commits so that commits[0] is the oldest and the last entry is HEAD
 	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
 		commits[i], commits[j] = commits[j], commits[i]
 	}
-	ctx.CommitsSinceLastTag = len(commits)
+	commitTraces := make(map[plumbing.Hash]*CommitTrace)
+	if ctx.Trace != nil {
+		ctx.Trace.Commits = make([]CommitTrace, len(traced))
+		for i := range traced {
+			ctx.Trace.Commits[len(traced)-1-i] = traced[i]
+		}
+		for i := range ctx.Trace.Commits {
+			if ctx.Trace.Commits[i].Skipped == "" {
+				commitTraces[plumbing.NewHash(ctx.Trace.Commits[i].SHA)] = &ctx.Trace.Commits[i]
+			}
+		}
+	}
+	if err := applyTrailerOverrides(ctx, commits); err != nil {
+		return false, err
+	}
+	// Without a base version there is nothing to bump: the commits are classified
+	// for the notes of the first release only.
+	if ctx.BaseVersion == nil {
+		for _, entry := range commitTraces {
+			entry.Skipped = "no base version to bump"
+		}
+		commitTraces = nil
+	}
+	// Cherry-picked copies of a change already in the range are counted once.
+	cherryPicks, duplicates, err := findCherryPicks(ctx.Config.CherryPicks, commits, diffs)
+	if err != nil {
+		return false, err
+	}
+
+	// Classify the commits that count, skipping reverted ones and cherry-picked copies.
+	ctx.Commits = nil
+	cancelledReverts, cancelled := findRevertPairs(commits)
+	pathRules, err := newPathRuleSet(ctx.Config.PathRules)
+	if err != nil {
+		return false, err
+	}
+	for _, pair := range cancelledReverts {
+		if entry := commitTraces[pair.Reverted.Hash]; entry != nil {
+			entry.Skipped = "reverted by " + shortSHA(pair.Revert)
+		}
+		if entry := commitTraces[pair.Revert.Hash]; entry != nil {
+			entry.Skipped = "reverts " + shortSHA(pair.Reverted)
+		}
+	}
+	for _, pair := range cherryPicks {
+		if entry := commitTraces[pair.CherryPick.Hash]; entry != nil && duplicates[pair.CherryPick.Hash] {
+			entry.Skipped = "cherry-pick of " + shortSHA(pair.Original)
+		}
+	}
+	var highestBump = noBump
+	bumpRule := "highest commit bump"
+	analysed := 0
+	for _, commit := range commits {
+		if cancelled[commit.Hash] || duplicates[commit.Hash] {
+			continue
+		}
+		messageBump, rule := classifyMessage(ctx.Config, commit.Message)
+		bump, counts := messageBump, true
+		// The diff of a merge holds the changes of the commits it merges, so path
+		// rules only bound it when those commits are not analysed on their own.
+		if !mergesWalkedCommits(commit, walked) {
+			bump, counts, err = pathRules.apply(commit, messageBump, diffs)
+			if err != nil {
+				return false, err
+			}
+		}
+		if counts {
+			analysed++
+		}
+		if entry := commitTraces[commit.Hash]; entry != nil {
+			if !counts {
+				rule += "; path rules cap it at none, so it does not count as a change"
+			} else if bump != messageBump {
+				rule += fmt.Sprintf("; path rules changed %s to %s", messageBump, bump)
+			}
+			entry.Bump, entry.Rule = bump.String(), rule
+		}
+		ctx.Commits = append(ctx.Commits, AnalysedCommit{Commit: commit, Bump: bump})
+		if bump > highestBump {
+			highestBump = bump
+		}
+	}
+
+	// The walk otherwise only serves to find a Release-As trailer; everything else
+	// is left to the configured-next-version strategy.
+	if ctx.BaseVersion == nil {
+		if ctx.ReleaseAs == nil {
+			return false, nil
+		}
+		ctx.NextVersion = semver.MustParse(ctx.ReleaseAs.Value)
+		return true, nil
+	}
+	ctx.CherryPicks, ctx.CancelledReverts = cherryPicks, cancelledReverts
+	ctx.CommitsSinceLastTag = len(commits) - len(duplicates)
+
+	// A Release-As trailer beats both commit message bumps and no-bump messages,
+	// but never moves the version backwards past an existing tag.
+	if ctx.ReleaseAs != nil {
+		v := semver.MustParse(ctx.ReleaseAs.Value)
+		if v.GreaterThan(ctx.BaseVersion) {
+			ctx.NextVersion = v
+			ctx.Trace.setBump(-1, fmt.Sprintf("%s trailer of %s", ReleaseAsTrailer, shortSHA(ctx.ReleaseAs.Commit)))
+			return true, nil
+		}
+		ctx.ReleaseAs = nil
+	}
 
 	// If the most recent commit matches no-bump-message, do not bump at all
 	if len(commits) > 0 {
 		if strings.Contains(commits[0].Message, "+semver: none") || strings.Contains(commits[0].Message, "+semver: skip") {
 			ctx.Bump = noBump
 			ctx.NextVersion = ctx.BaseVersion
+			ctx.Trace.setBump(noBump, "+semver: none or skip in "+shortSHA(commits[0]))
 			return true, nil // No bump if no-bump-message found
 		}
 	}
@@ -225,15 +433,14 @@ func (s *IncrementFromCommitsStrategy) Execute(ctx *VersionContext) (bool, error
 		if matched {
 			ctx.Bump = noBump
 			ctx.NextVersion = ctx.BaseVersion
+			ctx.Trace.setBump(noBump, "no-bump-message matches "+shortSHA(commits[0]))
 			return true, nil // No bump if no-bump-message found
 		}
 	}
-	var highestBump = noBump
-	for _, commit := range commits {
-		bump := getBumpFromMessage(ctx.Config, commit.Message)
-		if bump > highestBump {
-			highestBump = bump
-		}
+	// An API change found by the api-diff strategy raises the bump to match it.
+	if ctx.APIDiff != nil && ctx.APIDiff.bump() > highestBump {
+		highestBump = ctx.APIDiff.bump()
+		bumpRule = fmt.Sprintf("api-diff found %s API changes", ctx.APIDiff.Compatibility)
 	}
 	branchConfig := ctx.Config.GetBranchConfig(ctx.CurrentBranchName)
 	// Handle semver-from-branch mode (for release branches)
@@ -251,12 +458,15 @@ func (s *IncrementFromCommitsStrategy) Execute(ctx *VersionContext) (bool, error
 				}
 				ctx.NextVersion = &ver
 				ctx.Bump = noBump
+				ctx.Trace.setBump(-1, "version from the branch name in semver-from-branch mode")
 				return true, nil
 			}
 		}
 	}
-	// Use increment setting if no bump detected
-	if highestBump == noBump && len(commits) > 0 && (branchConfig == nil || !branchConfig.PreventIncrement) {
+	// Use increment setting if no bump detected. A range in which every commit
+	// was reverted again, or capped at none by a path rule, holds no changes,
+	// so it is not incremented either.
+	if highestBump == noBump && analysed > 0 && (branchConfig == nil || !branchConfig.PreventIncrement) {
 		// Only apply increment setting for the *first* commit after the tag
 		increment := ""
 		if branchConfig != nil && branchConfig.Increment != "" {
@@ -264,7 +474,9 @@ func (s *IncrementFromCommitsStrategy) Execute(ctx *VersionContext) (bool, error
 		} else if ctx.Config.Increment != "" {
 			increment = strings.ToLower(ctx.Config.Increment)
 		}
+		bumpRule = "default patch increment for commits without a bump"
 		if increment != "" {
+			bumpRule = "increment setting " + increment
 			switch increment {
 			case "major":
 				highestBump = majorBump
@@ -280,6 +492,7 @@ func (s *IncrementFromCommitsStrategy) Execute(ctx *VersionContext) (bool, error
 		}
 	}
 	ctx.Bump = highestBump
+	ctx.Trace.setBump(highestBump, bumpRule)
 	if highestBump != noBump {
 		nextVersion := *ctx.BaseVersion
 		switch highestBump {
@@ -296,6 +509,69 @@ func (s *IncrementFromCommitsStrategy) Execute(ctx *VersionContext) (bool, error
 	return false, nil // No increment found
 }
 
+// commitsSinceBase iterates the commits reachable from ctx.HeadCommit, newest
+// first; the caller stops at the base version commit. With cherry-pick detection
+// enabled, every ancestor of the base version commit is excluded instead, so that
+// the walk also reaches the branches merged in since, where the copies of a
+// cherry-picked change usually are.
+func commitsSinceBase(ctx *VersionContext) (object.CommitIter, error) {
+	if ctx.HeadCommit == nil {
+		return nil, errors.New("repository has no HEAD commit")
+	}
+	cherryPicks := ctx.Config.CherryPicks
+	if ctx.BaseVersionCommit == nil || (!cherryPicks.Trailer && !cherryPicks.PatchID) {
+		return object.NewCommitPreorderIter(ctx.HeadCommit, nil, nil), nil
+	}
+
+	released := make(map[plumbing.Hash]bool)
+	err := object.NewCommitPreorderIter(ctx.BaseVersionCommit, nil, nil).ForEach(func(c *object.Commit) error {
+		released[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return object.NewCommitPreorderIter(ctx.HeadCommit, released, nil), nil
+}
+
+// mergesWalkedCommits reports whether c is a merge that brings in a commit of the walk.
+func mergesWalkedCommits(c *object.Commit, walked map[plumbing.Hash]bool) bool {
+	if c.NumParents() < 2 {
+		return false
+	}
+	for _, parent := range c.ParentHashes[1:] {
+		if walked[parent] {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTrailerOverrides records the newest Release-As and Semver-Label trailers found
+// in commits, which must be ordered with the oldest commit first.
+func applyTrailerOverrides(ctx *VersionContext, commits []*object.Commit) error {
+	ctx.ReleaseAs = nil
+	ctx.SemverLabel = nil
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		trailers := ParseTrailers(c.Message)
+		if ctx.ReleaseAs == nil {
+			if value, ok := trailerValue(trailers, ReleaseAsTrailer); ok {
+				if _, err := semver.NewVersion(value); err != nil {
+					return fmt.Errorf("invalid %s trailer in commit %s: %w", ReleaseAsTrailer, c.Hash, err)
+				}
+				ctx.ReleaseAs = &TrailerOverride{Trailer: ReleaseAsTrailer, Value: value, Commit: c}
+			}
+		}
+		if ctx.SemverLabel == nil {
+			if value, ok := trailerValue(trailers, SemverLabelTrailer); ok {
+				ctx.SemverLabel = &TrailerOverride{Trailer: SemverLabelTrailer, Value: value, Commit: c}
+			}
+		}
+	}
+	return nil
+}
+
 // ConfiguredNextVersionStrategy provides a version based on the 'next-version' configuration.
 type ConfiguredNextVersionStrategy struct{}
 
@@ -320,6 +596,7 @@ func (s *ConfiguredNextVersionStrategy) Execute(ctx *VersionContext) (bool, erro
 
 var strategyFactories = map[string]func() VersioningStrategy{
 	"find-latest-tag":         func() VersioningStrategy { return &FindLatestTagStrategy{} },
+	"api-diff":                func() VersioningStrategy { return &APIDiffStrategy{} },
 	"increment-from-commits":  func() VersioningStrategy { return &IncrementFromCommitsStrategy{} },
 	"configured-next-version": func() VersioningStrategy { return &ConfiguredNextVersionStrategy{} },
 }
@@ -0,0 +1,96 @@
+package gitversion
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// TagConfig configures the tag command.
+type TagConfig struct {
+	// Name is a Go template for the tag name, rendered with the version variables.
+	// By default the new tag is named like the base version's tag.
+	Name string `yaml:"name,omitempty"`
+	// Annotated creates annotated tags instead of lightweight ones.
+	Annotated bool `yaml:"annotated,omitempty"`
+	// Message is a Go template for the annotation, rendered with the version
+	// variables, the tag name as .Tag and the release notes as .ReleaseNotes.
+	Message string `yaml:"message,omitempty"`
+	// SigningKey is the key to sign annotated tags with: a file, relative to the
+	// repository root, or for the gpg format a key ID.
+	SigningKey string `yaml:"signing-key,omitempty"`
+	// SigningFormat is how tags are signed: openpgp (the default), gpg or ssh; see
+	// SigningFormatOpenPGP.
+	SigningFormat string `yaml:"signing-format,omitempty"`
+}
+
+// NewVersionTag returns the default name of the tag for version: named like the
+// base version's tag, e.g. v1.3.0 after v1.2.0, or with a "v", "<project>/v" or
+// "<module dir>/v" prefix for the first release.
+func (ctx *VersionContext) NewVersionTag(version string) (string, error) {
+	previousTag, err := ctx.BaseVersionTag()
+	if err != nil {
+		return "", err
+	}
+	if previousTag != "" && strings.Contains(previousTag, ctx.BaseVersion.String()) {
+		return strings.Replace(previousTag, ctx.BaseVersion.String(), version, 1), nil
+	}
+
+	prefix := "v"
+	if ctx.Config.goModule.Path != "" {
+		if dir := ctx.Config.goModule.TagDir(); dir != "" {
+			prefix = dir + "/v"
+		}
+	} else if ctx.ProjectName != "" {
+		prefix = ctx.ProjectName + "/v"
+	}
+	return prefix + version, nil
+}
+
+// ParseVersionTag returns the version a tag name stands for under the configured
+// tag prefix, if any.
+func (ctx *VersionContext) ParseVersionTag(name string) (*semver.Version, bool) {
+	return tagVersion(ctx.Config, name)
+}
+
+// VersionTagsOn returns the names of the version tags on a commit.
+func (ctx *VersionContext) VersionTagsOn(hash plumbing.Hash) ([]string, error) {
+	tags, err := ctx.tagIndex()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, tag := range tags.TagsOn(hash) {
+		if _, ok := tagVersion(ctx.Config, tag.Name); ok {
+			names = append(names, tag.Name)
+		}
+	}
+	return names, nil
+}
+
+// FindVersionTag returns the name of a tag for version anywhere in the repository,
+// or "" when the version has not been tagged.
+func (ctx *VersionContext) FindVersionTag(version *semver.Version) (string, error) {
+	tags, err := ctx.tagIndex()
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags.Tags {
+		if v, ok := tagVersion(ctx.Config, tag.Name); ok && v.Equal(version) {
+			return tag.Name, nil
+		}
+	}
+	return "", nil
+}
+
+func (ctx *VersionContext) tagIndex() (*TagIndex, error) {
+	if ctx.Tags == nil {
+		tags, err := NewTagIndex(ctx.Repository)
+		if err != nil {
+			return nil, err
+		}
+		ctx.Tags = tags
+	}
+	return ctx.Tags, nil
+}
@@ -0,0 +1,126 @@
+package gitversion
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Trace records the decisions a calculation made, for calculate --explain.
+type Trace struct {
+	Branch string `json:"Branch"`
+	// BranchPattern is the key of the branches config section that matched, if any.
+	BranchPattern string          `json:"BranchPattern,omitempty"`
+	Strategies    []StrategyTrace `json:"Strategies"`
+	// Tags lists the tags considered as the base version, in the order they were
+	// looked at.
+	Tags        []TagTrace    `json:"Tags,omitempty"`
+	BaseVersion string        `json:"BaseVersion,omitempty"`
+	BaseCommit  string        `json:"BaseCommit,omitempty"`
+	Commits     []CommitTrace `json:"Commits,omitempty"` // oldest first
+	Bump        string        `json:"Bump,omitempty"`
+	BumpRule    string        `json:"BumpRule,omitempty"`
+	NextVersion string        `json:"NextVersion"`
+}
+
+// StrategyTrace is one strategy of the pipeline and what it did. Strategies after
+// the one that produced the version do not run.
+type StrategyTrace struct {
+	Name    string `json:"Name"`
+	Ran     bool   `json:"Ran"`
+	Outcome string `json:"Outcome"`
+}
+
+// TagTrace is a tag considered as the base version. Rejected gives the reason a
+// tag was not a candidate; Selected marks the candidate that became the base version.
+type TagTrace struct {
+	Name     string `json:"Name"`
+	Source   string `json:"Source"` // "branch <name>" for source branches, or "all tags"
+	Version  string `json:"Version,omitempty"`
+	Commit   string `json:"Commit,omitempty"`
+	Rejected string `json:"Rejected,omitempty"`
+	Selected bool   `json:"Selected,omitempty"`
+}
+
+// CommitTrace is a commit since the base version with the bump it contributes and
+// the rule that decided it. Skipped gives the reason a commit was not classified.
+type CommitTrace struct {
+	SHA     string `json:"SHA"`
+	Message string `json:"Message"` // the header line
+	Bump    string `json:"Bump,omitempty"`
+	Rule    string `json:"Rule,omitempty"`
+	Skipped string `json:"Skipped,omitempty"`
+}
+
+func newCommitTrace(c *object.Commit) CommitTrace {
+	return CommitTrace{SHA: c.Hash.String(), Message: strings.TrimSpace(strings.Split(c.Message, "\n")[0])}
+}
+
+func newTagTrace(tag IndexedTag, source string, v *semver.Version, c *object.Commit, rejected string) TagTrace {
+	entry := TagTrace{Name: tag.Name, Source: source, Commit: c.Hash.String(), Rejected: rejected}
+	if v != nil {
+		entry.Version = v.String()
+	}
+	return entry
+}
+
+// addTags appends the tags considered by one search and marks the one holding
+// the selected version.
+func (t *Trace) addTags(tags []TagTrace, selected *semver.Version, selectedCommit *object.Commit) {
+	if t == nil {
+		return
+	}
+	for i := range tags {
+		if selected != nil && tags[i].Rejected == "" && tags[i].Version == selected.String() &&
+			tags[i].Commit == selectedCommit.Hash.String() {
+			tags[i].Selected = true
+			selected = nil
+		}
+	}
+	t.Tags = append(t.Tags, tags...)
+}
+
+// setBump records the bump and the rule that decided it; a negative bump records
+// a rule that sets the version directly.
+func (t *Trace) setBump(bump semverBump, rule string) {
+	if t == nil {
+		return
+	}
+	t.Bump, t.BumpRule = "", rule
+	if bump >= noBump {
+		t.Bump = bump.String()
+	}
+}
+
+// strategyName returns the name a strategy is configured by.
+func strategyName(strategy VersioningStrategy) string {
+	for name, factory := range strategyFactories {
+		if reflect.TypeOf(factory()) == reflect.TypeOf(strategy) {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", strategy)
+}
+
+// strategyOutcome describes what a strategy that ran changed in the context.
+func strategyOutcome(ctx *VersionContext, success, hadBaseVersion, hadAPIDiff bool) string {
+	switch {
+	case success && ctx.ReleaseAs != nil:
+		return fmt.Sprintf("produced version %s from the %s trailer of %s", ctx.NextVersion, ctx.ReleaseAs.Trailer, shortSHA(ctx.ReleaseAs.Commit))
+	case success:
+		return fmt.Sprintf("produced version %s", ctx.NextVersion)
+	case !hadBaseVersion && ctx.BaseVersion != nil:
+		return fmt.Sprintf("found base version %s", ctx.BaseVersion)
+	case !hadAPIDiff && ctx.APIDiff != nil:
+		return fmt.Sprintf("found %s API changes", ctx.APIDiff.Compatibility)
+	default:
+		return "produced no version"
+	}
+}
+
+func shortSHA(c *object.Commit) string {
+	return c.Hash.String()[:7]
+}
@@ -0,0 +1,65 @@
+package gitversion
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	// ReleaseAsTrailer forces the next version, e.g. "Release-As: 3.0.0".
+	ReleaseAsTrailer = "Release-As"
+	// SemverLabelTrailer overrides the pre-release label, e.g. "Semver-Label: rc".
+	SemverLabelTrailer = "Semver-Label"
+)
+
+var trailerLineRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)\s*:\s*(.*)$`)
+
+// Trailer is a single "Key: value" line from the trailer block of a commit message.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// TrailerOverride records a version override carried by a commit trailer.
+type TrailerOverride struct {
+	Trailer string
+	Value   string
+	Commit  *object.Commit
+}
+
+// ParseTrailers returns the trailers found in the last paragraph of a commit message.
+// The subject line is never treated as a trailer block, and a paragraph only counts
+// as a trailer block when every non-empty line in it is a "Key: value" pair.
+func ParseTrailers(message string) []Trailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	var trailers []Trailer
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		matches := trailerLineRegex.FindStringSubmatch(line)
+		if matches == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: matches[1], Value: strings.TrimSpace(matches[2])})
+	}
+	return trailers
+}
+
+// trailerValue returns the last value of the named trailer, matching keys case-insensitively.
+func trailerValue(trailers []Trailer, key string) (string, bool) {
+	value, found := "", false
+	for _, t := range trailers {
+		if strings.EqualFold(t.Key, key) {
+			value, found = t.Value, true
+		}
+	}
+	return value, found
+}
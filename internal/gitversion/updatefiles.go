@@ -0,0 +1,18 @@
+package gitversion
+
+// UpdateFilesConfig selects the files the update-files command writes the version into.
+type UpdateFilesConfig struct {
+	// Files lists manifests, recognised by their file name: package.json, Chart.yaml,
+	// Cargo.toml, pom.xml and pyproject.toml. When empty, those present at the
+	// repository root are updated.
+	Files []string `yaml:"files,omitempty"`
+	// Rules update arbitrary files by regex.
+	Rules []UpdateFileRule `yaml:"rules,omitempty"`
+}
+
+// UpdateFileRule replaces the first capture group of every match of Pattern in File
+// with the version.
+type UpdateFileRule struct {
+	File    string `yaml:"file"`
+	Pattern string `yaml:"pattern"`
+}
@@ -0,0 +1,360 @@
+package gitversion
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5/plumbing"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigError is a problem in a configuration file, at the position of the
+// offending key or value. Line and Column are 1-based; Column is 0 when the YAML
+// parser only reports a line.
+type ConfigError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e ConfigError) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// ConfigErrors are all the problems found in a configuration file, in document order.
+type ConfigErrors []ConfigError
+
+func (e ConfigErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Values accepted by the mode, increment and signing-format settings, compared
+// case-insensitively.
+var (
+	validModes          = []string{"ContinuousDelivery", "ContinuousDeployment", "Mainline", "semver-from-branch"}
+	validIncrements     = []string{"Major", "Minor", "Patch"}
+	validSigningFormats = []string{SigningFormatOpenPGP, SigningFormatGPG, SigningFormatSSH}
+)
+
+// valueChecks validate the scalar values at a path of the document, where "*"
+// stands for any key of a map and "[]" for any item of a list. Given the name of
+// the setting and its value, they return the problem, or "".
+var valueChecks = map[string]func(name, value string) string{
+	"next-version":                          checkNextVersion,
+	"major-version-bump-message":            checkRegex,
+	"minor-version-bump-message":            checkRegex,
+	"patch-version-bump-message":            checkRegex,
+	"no-bump-message":                       checkRegex,
+	"tag-prefix":                            checkRegex,
+	"increment":                             checkIncrement(false),
+	"strategies[]":                          checkStrategy,
+	"merge-message-formats[]":               checkRegex,
+	"ignore.commits-before":                 checkIgnoreDate,
+	"ignore.paths[]":                        checkGlob,
+	"ignore.author-emails[]":                checkRegex,
+	"ignore.committer-emails[]":             checkRegex,
+	"ignore.messages[]":                     checkRegex,
+	"path-rules[].paths[]":                  checkGlob,
+	"path-rules[].min":                      checkBump,
+	"path-rules[].max":                      checkBump,
+	"branches.*.mode":                       checkMode,
+	"branches.*.increment":                  checkIncrement(true),
+	"branches.*.strategies[]":               checkStrategy,
+	"branches.*.source-branches[]":          checkBranchName,
+	"projects.*.exclude-paths[]":            checkGlob,
+	"projects.*.tag-prefix":                 checkRegex,
+	"projects.*.next-version":               checkNextVersion,
+	"projects.*.increment":                  checkIncrement(false),
+	"projects.*.major-version-bump-message": checkRegex,
+	"projects.*.minor-version-bump-message": checkRegex,
+	"projects.*.patch-version-bump-message": checkRegex,
+	"projects.*.no-bump-message":            checkRegex,
+	"projects.*.path-rules[].paths[]":       checkGlob,
+	"projects.*.path-rules[].min":           checkBump,
+	"projects.*.path-rules[].max":           checkBump,
+	"yanked[]":                              checkVersion,
+	"issues[].pattern":                      checkRegex,
+	"update-files.rules[].pattern":          checkRegex,
+	"tag.signing-format":                    checkSigningFormat,
+}
+
+// keyChecks validate the keys of the maps at a path of the document.
+var keyChecks = map[string]func(name, key string) string{
+	"branches": checkRegex,
+}
+
+// ValidateConfig checks the content of a configuration file strictly: every key
+// has to be known, every value of the right type, every regex has to compile,
+// and next-version, mode, increment, strategies and source-branches have to hold
+// valid values. Merge keys are checked as the mappings they merge. file only names
+// the file in the errors.
+func ValidateConfig(file string, data []byte) ConfigErrors {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return ConfigErrors{syntaxError(file, err)}
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	v := &configValidator{file: file}
+	v.check(doc.Content[0], reflect.TypeOf(Config{}), "", "")
+	return v.errs
+}
+
+var yamlLineRegex = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func syntaxError(file string, err error) ConfigError {
+	if m := yamlLineRegex.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return ConfigError{File: file, Line: line, Message: m[2]}
+	}
+	return ConfigError{File: file, Line: 1, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+}
+
+type configValidator struct {
+	file string
+	errs ConfigErrors
+}
+
+func (v *configValidator) fail(node *yaml.Node, format string, args ...any) {
+	v.errs = append(v.errs, ConfigError{File: v.file, Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)})
+}
+
+// check validates node as a value of type t. path is where the node is, as used
+// by valueChecks and keyChecks, and name how errors refer to it.
+func (v *configValidator) check(node *yaml.Node, t reflect.Type, path, name string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node.Tag == "!!null" {
+		return
+	}
+
+	// An ignore section may also be a plain list of SHAs; see IgnoreConfig.UnmarshalYAML.
+	if t == reflect.TypeOf(IgnoreConfig{}) && node.Kind == yaml.SequenceNode {
+		v.check(node, reflect.TypeOf([]string{}), joinPath(path, "shas"), joinName(name, "shas"))
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			v.fail(node, "%s must be a mapping", describe(name))
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" {
+				v.checkMerge(value, t, path, name)
+				continue
+			}
+			field, ok := fields[key.Value]
+			if !ok {
+				v.fail(key, "unknown key %q in %s", key.Value, describe(name))
+				continue
+			}
+			v.check(value, field.Type, joinPath(path, key.Value), joinName(name, key.Value))
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			v.fail(node, "%s must be a mapping", describe(name))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" {
+				v.checkMerge(value, t, path, name)
+				continue
+			}
+			if check := keyChecks[path]; check != nil {
+				if problem := check(name+" pattern", key.Value); problem != "" {
+					v.fail(key, "%s", problem)
+				}
+			}
+			v.check(value, t.Elem(), joinPath(path, "*"), joinName(name, strconv.Quote(key.Value)))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			v.fail(node, "%s must be a list", describe(name))
+			return
+		}
+		for i, item := range node.Content {
+			// Items of lists of sections are numbered, like in the errors of a calculation.
+			itemName := name
+			if elem := t.Elem(); elem.Kind() == reflect.Struct || elem.Kind() == reflect.Pointer {
+				itemName = fmt.Sprintf("%s[%d]", name, i)
+			}
+			v.check(item, t.Elem(), path+"[]", itemName)
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			v.fail(node, "%s must be a %s", describe(name), t.Kind())
+			return
+		}
+		if err := node.Decode(reflect.New(t).Interface()); err != nil {
+			v.fail(node, "%s must be a %s, got %q", describe(name), t.Kind(), node.Value)
+			return
+		}
+		if check := valueChecks[path]; check != nil {
+			if problem := check(name, node.Value); problem != "" {
+				v.fail(node, "%s", problem)
+			}
+		}
+	}
+}
+
+// checkMerge validates the value of a merge key, <<, as mappings of type t: a
+// mapping, an alias of one, or a list of them.
+func (v *configValidator) checkMerge(node *yaml.Node, t reflect.Type, path, name string) {
+	if node.Kind == yaml.SequenceNode {
+		for _, item := range node.Content {
+			v.check(item, t, path, name)
+		}
+		return
+	}
+	v.check(node, t, path, name)
+}
+
+// yamlFields returns the fields of a struct type by their YAML key.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func joinName(name, key string) string {
+	if name == "" {
+		return key
+	}
+	return name + " " + key
+}
+
+func describe(name string) string {
+	if name == "" {
+		return "the configuration"
+	}
+	return name
+}
+
+func checkRegex(name, s string) string {
+	if _, err := regexp.Compile(s); err != nil {
+		return fmt.Sprintf("invalid %s: %v", name, err)
+	}
+	return ""
+}
+
+func checkGlob(name, s string) string {
+	if _, err := compilePathGlob(s); err != nil {
+		return fmt.Sprintf("invalid %s: %v", name, err)
+	}
+	return ""
+}
+
+func checkIncrement(inherit bool) func(name, value string) string {
+	values := validIncrements
+	if inherit {
+		values = append(values[:len(values):len(values)], InheritValue)
+	}
+	return func(name, s string) string { return checkOneOf(name, s, values) }
+}
+
+func checkMode(name, s string) string {
+	return checkOneOf(name, s, validModes)
+}
+
+func checkSigningFormat(name, s string) string {
+	return checkOneOf(name, s, validSigningFormats)
+}
+
+func checkOneOf(name, s string, values []string) string {
+	if s == "" {
+		return ""
+	}
+	for _, value := range values {
+		if strings.EqualFold(s, value) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("invalid %s %q: expected %s", name, s, strings.Join(values, ", "))
+}
+
+func checkStrategy(name, s string) string {
+	if _, ok := strategyFactories[s]; !ok {
+		return fmt.Sprintf("unknown strategy %q in %s", s, name)
+	}
+	return ""
+}
+
+func checkBranchName(name, s string) string {
+	if err := plumbing.NewBranchReferenceName(s).Validate(); err != nil {
+		return fmt.Sprintf("invalid %s %q: not a valid branch name", name, s)
+	}
+	return ""
+}
+
+func checkBump(name, s string) string {
+	if _, err := parseBump(s); err != nil {
+		return fmt.Sprintf("invalid %s: %v", name, err)
+	}
+	return ""
+}
+
+func checkIgnoreDate(_, s string) string {
+	if _, err := parseIgnoreDate(s); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func checkNextVersion(name, s string) string {
+	if s == "" {
+		return ""
+	}
+	if _, err := semver.NewVersion(s); err != nil {
+		return fmt.Sprintf("invalid %s %q: %v", name, s, err)
+	}
+	return ""
+}
+
+func checkVersion(name, s string) string {
+	if _, err := semver.NewVersion(s); err != nil {
+		return fmt.Sprintf("invalid %s version %q: %v", name, s, err)
+	}
+	return ""
+}
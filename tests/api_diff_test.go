@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+const apiDiffBase = `package lib
+
+import "strings"
+
+// Client talks to the service.
+type Client struct {
+	Name string
+}
+
+// Greet returns a greeting.
+func (c *Client) Greet() string { return strings.ToUpper("hello " + c.Name) }
+
+// New creates a client.
+func New(name string) *Client { return &Client{Name: name} }
+
+func helper() {}
+`
+
+func newAPIDiffRepo(t *testing.T) *testRepo {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "strategies:\n  - find-latest-tag\n  - api-diff\n  - increment-from-commits\n")
+	repo.writeFile("go.mod", "module example.com/lib\n\ngo 1.24\n")
+	repo.writeFile("lib/lib.go", apiDiffBase)
+	repo.writeFile("lib/internal/impl/impl.go", "package impl\n\nfunc Do() {}\n")
+	repo.tag("v1.2.3", repo.commit("initial commit"))
+	return repo
+}
+
+func calculateAPIDiff(t *testing.T, repo *testRepo) app.VersionVariables {
+	t.Helper()
+	var out bytes.Buffer
+	err := app.RunCalculate(fs.NewOsFs(), &out, repo.path, "json")
+	require.NoError(t, err)
+	var vars app.VersionVariables
+	require.NoError(t, json.Unmarshal(out.Bytes(), &vars), out.String())
+	return vars
+}
+
+func TestAPIDiffBreakingChange(t *testing.T) {
+	repo := newAPIDiffRepo(t)
+	repo.writeFile("lib/lib.go", `package lib
+
+type Client struct {
+	Name string
+}
+
+func (c Client) Greet() string { return "hello " + c.Name }
+
+func New(name string, retries int) *Client { return &Client{Name: name} }
+`)
+	repo.commit("fix: tidy up client")
+
+	vars := calculateAPIDiff(t, repo)
+	assert.Equal(t, "2.0.0", vars.FullSemVer, "the API diff outranks the fix commit")
+	assert.Equal(t, "breaking", vars.APICompatibility)
+	assert.Equal(t, []string{"example.com/lib/lib.Client.Greet", "example.com/lib/lib.New"}, vars.APIIncompatible)
+}
+
+func TestAPIDiffAdditiveChange(t *testing.T) {
+	repo := newAPIDiffRepo(t)
+	repo.writeFile("lib/lib.go", apiDiffBase+`
+// Options configures a client.
+type Options struct {
+	Timeout int
+}
+`)
+	repo.writeFile("lib/internal/impl/impl.go", "package impl\n\nfunc Do(n int) {}\n")
+	repo.commit("chore: options")
+
+	vars := calculateAPIDiff(t, repo)
+	assert.Equal(t, "1.3.0", vars.FullSemVer, "internal packages are not part of the API")
+	assert.Equal(t, "additive", vars.APICompatibility)
+	assert.Empty(t, vars.APIIncompatible)
+}
+
+func TestAPIDiffCommitBumpWins(t *testing.T) {
+	repo := newAPIDiffRepo(t)
+	repo.writeFile("lib/lib.go", apiDiffBase+"\nfunc (c *Client) Close() error { return nil }\n")
+	repo.commit("feat!: add Close")
+
+	vars := calculateAPIDiff(t, repo)
+	assert.Equal(t, "2.0.0", vars.FullSemVer, "the commit message bump wins when it is higher")
+	assert.Equal(t, "additive", vars.APICompatibility)
+}
+
+func TestAPIDiffNoChange(t *testing.T) {
+	repo := newAPIDiffRepo(t)
+	repo.writeFile("lib/lib.go", apiDiffBase+"\nfunc anotherHelper() {}\n")
+	repo.commit("refactor: helpers")
+
+	vars := calculateAPIDiff(t, repo)
+	assert.Equal(t, "1.2.4", vars.FullSemVer)
+	assert.Equal(t, "none", vars.APICompatibility)
+}
@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+var changelogTime = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+func (r *testRepo) commitFile(name, msg string) plumbing.Hash {
+	r.writeFile(name, msg)
+	return r.commitAs(msg, &object.Signature{Name: "Test", Email: "test@example.com", When: changelogTime})
+}
+
+func runChangelog(t *testing.T, repo *testRepo, opts app.ChangelogOptions) string {
+	t.Helper()
+	var out bytes.Buffer
+	require.NoError(t, app.RunChangelog(fs.NewOsFs(), &out, repo.path, opts))
+	return out.String()
+}
+
+func short(h plumbing.Hash) string {
+	return h.String()[:7]
+}
+
+func TestChangelogGroupsCommits(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.2.0", repo.commitFile("a.txt", "initial commit"))
+	feat := repo.commitFile("b.txt", "feat(api): add pagination")
+	fix := repo.commitFile("c.txt", "fix: handle empty pages")
+	repo.commitFile("d.txt", "docs: explain pagination")
+	perf := repo.commitFile("e.txt", "perf(db): batch queries")
+	breaking := repo.commitFile("f.txt", "feat!: drop v1 endpoints")
+	reverted := repo.commitFile("g.txt", "fix: flaky retry")
+	repo.commitFile("h.txt", fmt.Sprintf("Revert \"fix: flaky retry\"\n\nThis reverts commit %s.", reverted))
+
+	assert.Equal(t, fmt.Sprintf(`## 2.0.0 (2026-10-17)
+
+### Breaking Changes
+
+- drop v1 endpoints (%s)
+
+### Features
+
+- **api:** add pagination (%s)
+
+### Bug Fixes
+
+- handle empty pages (%s)
+
+### Performance
+
+- **db:** batch queries (%s)
+`, short(breaking), short(feat), short(fix), short(perf)), runChangelog(t, repo, app.ChangelogOptions{}))
+}
+
+func TestChangelogFollowsPathRules(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "path-rules:\n  - paths: [\"docs/**\"]\n    max: none\n")
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("docs/guide.md", "feat: new guide")
+	fix := repo.commitFile("src/main.go", "fix: crash")
+
+	assert.Equal(t, fmt.Sprintf("## 1.0.1 (2026-10-17)\n\n### Bug Fixes\n\n- crash (%s)\n", short(fix)),
+		runChangelog(t, repo, app.ChangelogOptions{}), "commits capped at none are not in the notes")
+}
+
+func TestChangelogBetweenVersions(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	feat := repo.commitFile("b.txt", "feat: first feature")
+	repo.tag("v1.1.0", feat)
+	fix := repo.commitFile("c.txt", "fix: first fix")
+	repo.tag("v1.1.1", fix)
+	repo.commitFile("d.txt", "feat: unreleased")
+
+	assert.Equal(t, fmt.Sprintf("## 1.1.1 (2026-10-17)\n\n### Features\n\n- first feature (%s)\n\n### Bug Fixes\n\n- first fix (%s)\n", short(feat), short(fix)),
+		runChangelog(t, repo, app.ChangelogOptions{From: "1.0.0", To: "v1.1.1"}))
+}
+
+func TestChangelogCustomTemplate(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "changelog:\n  template: \"{{.PreviousVersion}} -> {{.Version}}:{{range .Sections}}{{range .Entries}} {{.Type}}/{{.Description}}{{end}}{{end}}\\n\"\n")
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("b.txt", "feat: one")
+
+	assert.Equal(t, "1.0.0 -> 1.1.0: feat/one\n", runChangelog(t, repo, app.ChangelogOptions{}))
+
+	templateFile := filepath.Join(t.TempDir(), "notes.tmpl")
+	require.NoError(t, os.WriteFile(templateFile, []byte("{{len .Sections}} section(s)\n"), 0644))
+	assert.Equal(t, "1 section(s)\n", runChangelog(t, repo, app.ChangelogOptions{Template: templateFile}))
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo.path, "notes.tmpl"), []byte("{{.Version}}\n"), 0644))
+	t.Chdir(t.TempDir())
+	assert.Equal(t, "1.1.0\n", runChangelog(t, repo, app.ChangelogOptions{Template: "notes.tmpl"}), "the template is resolved against the repository")
+}
+
+func TestChangelogFirstRelease(t *testing.T) {
+	repo := newTestRepo(t)
+	fix := repo.commitFile("a.txt", "fix: first fix")
+	feat := repo.commitFile("b.txt", "feat: first feature")
+
+	assert.Equal(t, fmt.Sprintf("## 0.1.0 (2026-10-17)\n\n### Features\n\n- first feature (%s)\n\n### Bug Fixes\n\n- first fix (%s)\n", short(feat), short(fix)),
+		runChangelog(t, repo, app.ChangelogOptions{}), "the notes of the first release cover every commit")
+}
+
+func TestChangelogUnknownVersion(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+
+	var out bytes.Buffer
+	err := app.RunChangelog(fs.NewOsFs(), &out, repo.path, app.ChangelogOptions{From: "0.9.0", To: "1.0.0"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no tag found for version 0.9.0")
+}
+
+const handEditedChangelog = `# Changelog
+
+Notes written by hand.
+
+## [Unreleased]
+
+- Something still in progress.
+
+## [1.0.0] - 2026-01-01
+
+### Features
+
+- Hand-edited description of the first release.
+
+[unreleased]: https://github.com/acme/app/compare/v1.0.0...HEAD
+[1.0.0]: https://github.com/acme/app/releases/tag/v1.0.0
+`
+
+func TestChangelogUpdate(t *testing.T) {
+	repo := newTestRepo(t)
+	_, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{"git@github.com:acme/app.git"}})
+	require.NoError(t, err)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	feat := repo.commitFile("b.txt", "feat(api): add pagination")
+
+	changelogFile := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	require.NoError(t, os.WriteFile(changelogFile, []byte(handEditedChangelog), 0644))
+
+	opts := app.ChangelogOptions{Update: changelogFile}
+	assert.Equal(t, "Updated "+changelogFile+" with 1.1.0\n", runChangelog(t, repo, opts))
+	data, err := os.ReadFile(changelogFile)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`# Changelog
+
+Notes written by hand.
+
+## [Unreleased]
+
+- Something still in progress.
+
+## [1.1.0] - 2026-10-17
+
+### Features
+
+- **api:** add pagination (%s)
+
+## [1.0.0] - 2026-01-01
+
+### Features
+
+- Hand-edited description of the first release.
+
+[unreleased]: https://github.com/acme/app/compare/v1.1.0...HEAD
+[1.1.0]: https://github.com/acme/app/compare/v1.0.0...v1.1.0
+[1.0.0]: https://github.com/acme/app/releases/tag/v1.0.0
+`, short(feat)), string(data))
+
+	assert.Equal(t, changelogFile+" already has 1.1.0\n", runChangelog(t, repo, opts))
+	again, err := os.ReadFile(changelogFile)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(again), "a second run changes nothing")
+}
+
+func TestChangelogUpdateResolvesFileAgainstRepository(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("b.txt", "fix: crash")
+	t.Chdir(t.TempDir())
+
+	var out bytes.Buffer
+	require.NoError(t, app.RunChangelog(fs.NewOsFs(), &out, repo.path, app.ChangelogOptions{Update: "CHANGELOG.md"}))
+	assert.Equal(t, "Updated CHANGELOG.md with 1.0.1\n", out.String())
+	data, err := os.ReadFile(filepath.Join(repo.path, "CHANGELOG.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "## [1.0.1] - 2026-10-17\n")
+	assert.NoFileExists(t, "CHANGELOG.md")
+}
+
+func TestChangelogUpdateCreatesFile(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "tag-prefix: release-\nchangelog:\n  compare-url: https://git.example.com/app/diff/{from}..{to}\n")
+	repo.tag("release-1.0.0", repo.commitFile("a.txt", "initial commit"))
+	fix := repo.commitFile("b.txt", "fix: crash")
+
+	changelogFile := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	runChangelog(t, repo, app.ChangelogOptions{Update: changelogFile})
+	data, err := os.ReadFile(changelogFile)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+
+## [Unreleased]
+
+## [1.0.1] - 2026-10-17
+
+### Bug Fixes
+
+- crash (%s)
+
+[unreleased]: https://git.example.com/app/diff/release-1.0.1..HEAD
+[1.0.1]: https://git.example.com/app/diff/release-1.0.0..release-1.0.1
+`, short(fix)), string(data))
+}
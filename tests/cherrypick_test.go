@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cherryPickOutput struct {
+	FullSemVer  string
+	CherryPicks []struct {
+		CherryPick string
+		Original   string
+	}
+}
+
+func calculateCherryPickOutput(t *testing.T, repo *testRepo) cherryPickOutput {
+	t.Helper()
+	cmd := exec.Command(binaryPath, "calculate", "--path", repo.path, "--output", "json")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	var out cherryPickOutput
+	require.NoError(t, json.Unmarshal(output, &out), string(output))
+	return out
+}
+
+func TestCherryPickTrailerCountedOnce(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v1.0.0", initialCommit)
+
+	repo.checkout("develop")
+	repo.writeFile("GitVersion.yml", `
+cherry-picks:
+  trailer: true
+branches:
+  develop:
+    tag: alpha
+`)
+	original := repo.commit("fix: a bug")
+	repo.writeFile("backport.txt", "backport")
+	backport := repo.commit(fmt.Sprintf("fix: a bug\n\n(cherry picked from commit %s)", original))
+
+	out := calculateCherryPickOutput(t, repo)
+	assert.Equal(t, "1.0.1-alpha.1", out.FullSemVer)
+	require.Len(t, out.CherryPicks, 1)
+	assert.Equal(t, backport.String(), out.CherryPicks[0].CherryPick)
+	assert.Equal(t, original.String(), out.CherryPicks[0].Original)
+}
+
+func TestCherryPickTrailerDisabledByDefault(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v1.0.0", initialCommit)
+
+	repo.checkout("develop")
+	repo.writeFile("GitVersion.yml", `
+branches:
+  develop:
+    tag: alpha
+`)
+	original := repo.commit("fix: a bug")
+	repo.writeFile("backport.txt", "backport")
+	repo.commit(fmt.Sprintf("fix: a bug\n\n(cherry picked from commit %s)", original))
+
+	out := calculateCherryPickOutput(t, repo)
+	assert.Equal(t, "1.0.1-alpha.2", out.FullSemVer)
+	assert.Empty(t, out.CherryPicks)
+}
+
+func TestCherryPickPatchIDCountedOnce(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", `
+cherry-picks:
+  patch-id: true
+branches:
+  develop:
+    tag: alpha
+`)
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v1.0.0", initialCommit)
+
+	// The fix lands on a hotfix branch first...
+	repo.checkout("hotfix/1.0.1")
+	repo.writeFile("fix.txt", "the fix\n")
+	hotfix := repo.commit("fix: a bug")
+
+	// ...and is applied again, without -x, on develop on top of other work.
+	repo.checkout("develop")
+	require.NoError(t, repo.worktree.Reset(&git.ResetOptions{Commit: initialCommit, Mode: git.HardReset}))
+	repo.writeFile("other.txt", "other work")
+	repo.commit("chore: other work")
+	repo.writeFile("fix.txt", "the fix\n")
+	backport := repo.commit("fix: a bug (backport)")
+
+	// Merging the hotfix back brings both copies into the range.
+	_, err := repo.worktree.Commit("Merge branch 'hotfix/1.0.1' into develop", &git.CommitOptions{
+		Author:            &object.Signature{Name: "Test", Email: "test@example.com"},
+		Parents:           []plumbing.Hash{backport, hotfix},
+		AllowEmptyCommits: true,
+	})
+	require.NoError(t, err)
+
+	out := calculateCherryPickOutput(t, repo)
+	assert.Equal(t, "1.0.1-alpha.3", out.FullSemVer)
+	require.Len(t, out.CherryPicks, 1)
+}
+
+func TestCherryPickPatchIDSkipsEmptyCommits(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", `
+cherry-picks:
+  patch-id: true
+`)
+	repo.tag("v1.0.0", repo.commit("initial commit"))
+
+	// Both empty commits count: the second one is not a copy of the first.
+	repo.checkout("develop")
+	for _, msg := range []string{"chore: release 1.0.1", "feat: switch on search"} {
+		_, err := repo.worktree.Commit(msg, &git.CommitOptions{
+			Author:            &object.Signature{Name: "Test", Email: "test@example.com"},
+			AllowEmptyCommits: true,
+		})
+		require.NoError(t, err)
+	}
+
+	out := calculateCherryPickOutput(t, repo)
+	assert.Equal(t, "1.1.0-alpha.2", out.FullSemVer)
+	assert.Empty(t, out.CherryPicks)
+}
+
+func TestMergedCommitsAnalysedOnlyWithCherryPickDetection(t *testing.T) {
+	newMergedRepo := func(config string) *testRepo {
+		repo := newTestRepo(t)
+		repo.writeFile("GitVersion.yml", config)
+		initialCommit := repo.commit("initial commit")
+		repo.tag("v1.0.0", initialCommit)
+
+		repo.checkout("topic")
+		repo.writeFile("topic.txt", "topic")
+		topic := repo.commit("chore: topic work")
+
+		repo.checkout("develop")
+		require.NoError(t, repo.worktree.Reset(&git.ResetOptions{Commit: initialCommit, Mode: git.HardReset}))
+		repo.writeFile("other.txt", "other work")
+		other := repo.commit("chore: other work")
+		_, err := repo.worktree.Commit("Merge branch 'topic' into develop", &git.CommitOptions{
+			Author:            &object.Signature{Name: "Test", Email: "test@example.com"},
+			Parents:           []plumbing.Hash{other, topic},
+			AllowEmptyCommits: true,
+		})
+		require.NoError(t, err)
+		return repo
+	}
+	branches := "branches:\n  develop:\n    tag: alpha\n"
+
+	// The walk stops at the tag, before reaching the merged branch.
+	out := calculateCherryPickOutput(t, newMergedRepo(branches))
+	assert.Equal(t, "1.0.1-alpha.2", out.FullSemVer)
+
+	out = calculateCherryPickOutput(t, newMergedRepo("cherry-picks:\n  trailer: true\n"+branches))
+	assert.Equal(t, "1.0.1-alpha.3", out.FullSemVer)
+}
@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+	"gitversion-go/internal/gitversion"
+)
+
+func runConfigShow(t *testing.T, repo *testRepo, branch string) string {
+	t.Helper()
+	var out bytes.Buffer
+	require.NoError(t, app.RunConfigShow(fs.NewOsFs(), &out, repo.path, branch))
+	return out.String()
+}
+
+func TestLoadConfigLayersFileOverDefaults(t *testing.T) {
+	config, err := gitversion.LoadConfig([]byte(`increment: Minor
+branches:
+  ^feature/:
+    tag: feat
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "[vV]?", config.TagPrefix)
+	assert.Equal(t, gitversion.SourceDefault, config.SettingSource("tag-prefix"))
+	assert.Equal(t, gitversion.SourceFile, config.SettingSource("increment"))
+
+	pattern, branch, inherited := config.ResolveBranchConfig("feature/search")
+	assert.Equal(t, "^feature/", pattern)
+	assert.Equal(t, "feat", branch.Tag)
+	assert.Equal(t, "Minor", branch.Increment)
+	assert.Equal(t, []string{"increment", "strategies"}, inherited)
+
+	pattern, branch, _ = config.ResolveBranchConfig("develop")
+	assert.Empty(t, pattern)
+	assert.Nil(t, branch)
+
+	config, err = gitversion.LoadConfig([]byte("increment: Minor\n"))
+	require.NoError(t, err)
+	pattern, branch, _ = config.ResolveBranchConfig("develop")
+	assert.Equal(t, "^develop$", pattern)
+	assert.Equal(t, "alpha", branch.Tag)
+	assert.Equal(t, gitversion.SourceDefault, config.BranchSource(pattern))
+}
+
+func TestBranchesSectionReplacesBuiltInBranches(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.checkout("release/2.0.0")
+	repo.commitFile("b.txt", "fix: correct typo")
+	repo.writeFile("GitVersion.yml", gitversion.GetWorkflowTemplate("GitHubFlow"))
+
+	vars := calculateJSON(t, repo)
+	assert.Equal(t, "1.0.1", vars.FullSemVer)
+}
+
+func TestDefaultBranchConfigLabelsDevelop(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.checkout("develop")
+	repo.commitFile("b.txt", "feat: add search")
+
+	vars := calculateJSON(t, repo)
+	assert.Equal(t, "1.1.0-alpha.1", vars.FullSemVer)
+}
+
+func TestConfigShowNotesSources(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", `tag-prefix: release-
+increment: Minor
+branches:
+  ^feature/:
+    tag: feat
+    increment: Inherit
+`)
+	repo.commitFile("a.txt", "initial commit")
+
+	assert.Equal(t, `# Configuration for branch feature/search
+next-version: 0.1.0 # default
+major-version-bump-message: ^(\s|\S)*?(\+semver:\s?(breaking|major)) # default
+minor-version-bump-message: ^(\s|\S)*?(\+semver:\s?(feature|minor)) # default
+patch-version-bump-message: ^(\s|\S)*?(\+semver:\s?(fix|patch)) # default
+no-bump-message: ^(\s|\S)*?(\+semver:\s?(none|skip)) # default
+tag-prefix: release- # GitVersion.yml
+increment: Minor # GitVersion.yml
+strategies: # default
+  - find-latest-tag
+  - increment-from-commits
+  - configured-next-version
+commit-date-format: 2006-01-02T15:04:05Z07:00 # default
+merge-message-formats: # default
+  - '^Merge pull request #'
+  - ^Merge branch '
+  - '^Merged in '
+branches:
+  ^feature/: # GitVersion.yml
+    mode: "" # GitVersion.yml
+    tag: feat # GitVersion.yml
+    increment: Minor # inherited
+    strategies: # inherited
+      - find-latest-tag
+      - increment-from-commits
+      - configured-next-version
+`, runConfigShow(t, repo, "feature/search"))
+
+	assert.Contains(t, runConfigShow(t, repo, "support/1.x"), "branches: {} # no pattern matches support/1.x\n")
+}
+
+func TestConfigValidateReportsPositions(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", `increment: Sometimes
+strategies: [find-latest-tag, guess]
+colour: blue
+branches:
+  ^feature/(:
+    mode: Turbo
+    source-branches: ["a..b"]
+  ^develop$:
+    is-release-branch: maybe
+`)
+	repo.commitFile("a.txt", "initial commit")
+
+	err := app.RunConfigValidate(fs.NewOsFs(), &bytes.Buffer{}, repo.path)
+	assert.EqualError(t, err, `invalid configuration:
+GitVersion.yml:1:12: invalid increment "Sometimes": expected Major, Minor, Patch
+GitVersion.yml:2:31: unknown strategy "guess" in strategies
+GitVersion.yml:3:1: unknown key "colour" in the configuration
+GitVersion.yml:5:3: invalid branches pattern: error parsing regexp: missing closing ): `+"`^feature/(`"+`
+GitVersion.yml:6:11: invalid branches "^feature/(" mode "Turbo": expected ContinuousDelivery, ContinuousDeployment, Mainline, semver-from-branch
+GitVersion.yml:7:23: invalid branches "^feature/(" source-branches "a..b": not a valid branch name
+GitVersion.yml:9:24: branches "^develop$" is-release-branch must be a bool, got "maybe"`)
+
+	err = app.RunCalculate(fs.NewOsFs(), &bytes.Buffer{}, repo.path, "json")
+	assert.ErrorContains(t, err, "GitVersion.yml:3:1: unknown key \"colour\" in the configuration")
+}
+
+func TestConfigValidateChecksNextVersionAndMergeKeys(t *testing.T) {
+	errs := gitversion.ValidateConfig("GitVersion.yml", []byte(`next-version: one.two
+branches:
+  ^release/: &release
+    mode: semver-from-branch
+    tag: beta
+  ^hotfix/:
+    <<: *release
+    tag: hotfix
+  ^support/:
+    <<: [*release, {mode: Turbo}]
+projects:
+  api:
+    path: api
+    next-version: 2.0.0
+`))
+	assert.EqualError(t, errs, `GitVersion.yml:1:15: invalid next-version "one.two": Invalid Semantic Version
+GitVersion.yml:10:27: invalid branches "^support/" mode "Turbo": expected ContinuousDelivery, ContinuousDeployment, Mainline, semver-from-branch`)
+
+	config, err := gitversion.LoadConfig([]byte(`branches:
+  ^release/: &release
+    mode: semver-from-branch
+    tag: beta
+  ^hotfix/:
+    <<: *release
+    tag: hotfix
+`))
+	require.NoError(t, err)
+	branch := config.GetBranchConfig("hotfix/1.2.1")
+	assert.Equal(t, "semver-from-branch", branch.Mode)
+	assert.Equal(t, "hotfix", branch.Tag)
+}
+
+func TestConfigValidateAcceptsWorkflowTemplates(t *testing.T) {
+	for _, workflow := range []string{"GitFlow", "GitHubFlow"} {
+		assert.Empty(t, gitversion.ValidateConfig("GitVersion.yml", []byte(gitversion.GetWorkflowTemplate(workflow))), workflow)
+	}
+	assert.Empty(t, gitversion.ValidateConfig("GitVersion.yml", []byte(gitversion.DefaultConfig)))
+
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", gitversion.GetWorkflowTemplate("GitFlow"))
+	var out bytes.Buffer
+	require.NoError(t, app.RunConfigValidate(fs.NewOsFs(), &out, repo.path))
+	assert.Equal(t, "GitVersion.yml is valid\n", out.String())
+}
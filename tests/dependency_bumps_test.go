@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+// newDependencyRepo creates four Go module projects: api requires core, web
+// replaces api with its directory, and other stands alone.
+func newDependencyRepo(t *testing.T) *testRepo {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", `
+projects:
+  core:
+    path: libs/core
+  api:
+    path: services/api
+  web:
+    path: services/web
+  other:
+    path: tools/other
+`)
+	repo.writeFile("libs/core/go.mod", "module example.com/libs/core\n\ngo 1.24\n")
+	repo.writeFile("services/api/go.mod", "module example.com/services/api\n\ngo 1.24\n\nrequire example.com/libs/core v1.2.0\n")
+	repo.writeFile("services/web/go.mod", "module example.com/services/web\n\ngo 1.24\n\nreplace example.com/services/api => ../api\n")
+	repo.writeFile("tools/other/go.mod", "module example.com/tools/other\n\ngo 1.24\n")
+	initialCommit := repo.commit("initial commit")
+	for _, tag := range []string{"core/v1.2.0", "api/v0.3.0", "web/v2.0.0", "other/v1.0.0"} {
+		repo.tag(tag, initialCommit)
+	}
+	return repo
+}
+
+// calculateProjectsByName runs calculate --all-projects and indexes the result.
+func calculateProjectsByName(t *testing.T, repo *testRepo) map[string]app.ProjectVersion {
+	t.Helper()
+	var out bytes.Buffer
+	err := app.RunCalculateWithOptions(fs.NewOsFs(), &out, repo.path, app.CalculateOptions{AllProjects: true})
+	require.NoError(t, err)
+
+	var projects []app.ProjectVersion
+	require.NoError(t, json.Unmarshal(out.Bytes(), &projects), out.String())
+	byName := make(map[string]app.ProjectVersion)
+	for _, p := range projects {
+		byName[p.Name] = p
+	}
+	return byName
+}
+
+func TestDependencyBumpsAcrossGoModules(t *testing.T) {
+	repo := newDependencyRepo(t)
+	repo.writeFile("libs/core/core.go", "package core")
+	repo.commit("feat: new core API")
+
+	byName := calculateProjectsByName(t, repo)
+
+	assert.Equal(t, "1.3.0", byName["core"].Variables.FullSemVer)
+	assert.Empty(t, byName["core"].BumpedBy)
+
+	assert.True(t, byName["api"].Changed)
+	assert.Equal(t, "0.3.1", byName["api"].Variables.FullSemVer)
+	assert.Equal(t, []string{"core"}, byName["api"].BumpedBy)
+
+	assert.True(t, byName["web"].Changed)
+	assert.Equal(t, "2.0.1", byName["web"].Variables.FullSemVer)
+	assert.Equal(t, []string{"api"}, byName["web"].BumpedBy)
+
+	assert.False(t, byName["other"].Changed)
+	assert.Equal(t, "1.0.0", byName["other"].Variables.FullSemVer)
+}
+
+func TestDependencyBumpsGetBranchLabel(t *testing.T) {
+	repo := newDependencyRepo(t)
+	repo.checkout("feature/core-api")
+	repo.writeFile("libs/core/core.go", "package core")
+	repo.commit("feat: new core API")
+
+	byName := calculateProjectsByName(t, repo)
+	assert.Equal(t, "1.3.0-feature-core-api.1", byName["core"].Variables.FullSemVer)
+	assert.Equal(t, "0.3.1-feature-core-api.1", byName["api"].Variables.FullSemVer)
+	assert.Equal(t, "2.0.1-feature-core-api.1", byName["web"].Variables.FullSemVer)
+	assert.Equal(t, "1.0.0", byName["other"].Variables.FullSemVer)
+}
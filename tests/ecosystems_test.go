@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+)
+
+func TestEcosystemVersionsForPrerelease(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	repo.tag("v1.1.0", repo.commit("initial commit"))
+
+	repo.checkout("develop")
+	repo.writeFile("GitVersion.yml", "branches:\n  develop:\n    tag: beta\n    pre-release-weight: 3\n")
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+		repo.writeFile(name, name)
+		repo.commit("feat: " + name)
+	}
+
+	vars := calculateJSON(t, repo)
+	assert.Equal(t, "1.2.0-beta.3.4", vars.FullSemVer)
+	assert.Equal(t, "1.2.0b3.dev4", vars.PEP440)
+	assert.Equal(t, "1.2.0~beta.3.4", vars.Debian)
+	assert.Equal(t, "1.2.0", vars.RPMVersion)
+	assert.Equal(t, "0.beta.3.4", vars.RPMRelease)
+	assert.Equal(t, "1.2.0-beta00030004", vars.NuGetLegacy)
+	assert.Equal(t, "1.2.0-SNAPSHOT", vars.Maven)
+	assert.Equal(t, "1.2.0-beta.3.4", vars.DockerTag)
+}
+
+func TestEcosystemVersionsKeepOrder(t *testing.T) {
+	calculate := func(weight string, commits int) app.VersionVariables {
+		repo := newTestRepo(t)
+		repo.writeFile("README.md", "initial commit")
+		repo.tag("v1.1.0", repo.commit("initial commit"))
+		repo.checkout("develop")
+		repo.writeFile("GitVersion.yml", "branches:\n  develop:\n    tag: beta\n    pre-release-weight: "+weight+"\n")
+		for i := range commits {
+			repo.writeFile("file.txt", strconv.Itoa(i))
+			repo.commit("feat: change " + strconv.Itoa(i))
+		}
+		return calculateJSON(t, repo)
+	}
+
+	older, newer := calculate("3", 4), calculate("4", 1)
+	require.Equal(t, "1.2.0-beta.3.4", older.FullSemVer)
+	require.Equal(t, "1.2.0-beta.4.1", newer.FullSemVer)
+	assert.Equal(t, "1.2.0-beta00040001", newer.NuGetLegacy)
+	assert.Less(t, older.NuGetLegacy, newer.NuGetLegacy, "every number is encoded")
+	assert.Equal(t, "1.2.0b4.dev1", newer.PEP440)
+
+	large := calculate("10000", 1)
+	require.Equal(t, "1.2.0-beta.10000.1", large.FullSemVer)
+	assert.Empty(t, large.NuGetLegacy, "numbers too large to pad are not encoded")
+}
+
+func TestEcosystemVersionsForBranchLabel(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	repo.tag("v1.1.0", repo.commit("initial commit"))
+
+	repo.checkout("release/1.2")
+	repo.writeFile("GitVersion.yml", "branches:\n  ^release/.*$:\n    tag: use-branch-name\n    is-release-branch: true\n")
+	repo.writeFile("a.txt", "a")
+	repo.commit("fix: a bug")
+
+	vars := calculateJSON(t, repo)
+	assert.Equal(t, "1.1.1-release-1.2.1", vars.FullSemVer)
+	assert.Equal(t, "1.1.1.dev100020001", vars.PEP440, "unknown labels become development releases")
+	assert.Equal(t, "1.1.1~release.1.2.1", vars.Debian)
+	assert.Equal(t, "1.1.1-release000100020001", vars.NuGetLegacy)
+	assert.Equal(t, "1.1.1-release-1.2.1", vars.Maven, "release branches do not build snapshots")
+}
+
+func TestEcosystemVersionsForStableRelease(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	repo.tag("v1.1.0", repo.commit("initial commit"))
+
+	vars := calculateJSON(t, repo)
+	assert.Equal(t, "1.1.0", vars.PEP440)
+	assert.Equal(t, "1.1.0", vars.Debian)
+	assert.Equal(t, "1", vars.RPMRelease)
+	assert.Equal(t, "1.1.0", vars.NuGetLegacy)
+	assert.Equal(t, "1.1.0-SNAPSHOT", vars.Maven, "master is not a release branch")
+	assert.Equal(t, "1.1.0", vars.DockerTag)
+
+	repo.writeFile("GitVersion.yml", "branches:\n  ^master$:\n    is-release-branch: true\n")
+	assert.Equal(t, "1.1.0", calculateJSON(t, repo).Maven)
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+	"gitversion-go/internal/gitversion"
+)
+
+func runExplain(t *testing.T, repo *testRepo, outputFormat string) string {
+	t.Helper()
+	var out bytes.Buffer
+	err := app.RunCalculateWithOptions(fs.NewOsFs(), &out, repo.path, app.CalculateOptions{OutputFormat: outputFormat, Explain: true})
+	require.NoError(t, err)
+	return out.String()
+}
+
+func TestExplainTree(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", `branches:
+  ^master$:
+    tag: ""
+ignore:
+  messages: ['^chore\(deps\)']
+`)
+	base := repo.commitFile("a.txt", "initial commit")
+	repo.tag("v1.0.0", base)
+	repo.tag("nightly", base)
+	feat := repo.commitFile("b.txt", "feat: add search")
+	deps := repo.commitFile("c.txt", "chore(deps): bump x")
+	fix := repo.commitFile("d.txt", "fix: flaky retry")
+	revert := repo.commitFile("e.txt", fmt.Sprintf("Revert \"fix: flaky retry\"\n\nThis reverts commit %s.", fix))
+	docs := repo.commitFile("f.txt", "docs: explain search")
+
+	out := runExplain(t, repo, "default")
+	assert.Contains(t, out, `Branch: master (matched branches config "^master$")
+Strategies
+├── find-latest-tag: found base version 1.0.0
+├── increment-from-commits: produced version 1.1.0
+└── configured-next-version: skipped: increment-from-commits already produced the version
+Tags
+`)
+	assert.Contains(t, out, "nightly (all tags) rejected: not a semantic version\n")
+	assert.Contains(t, out, "v1.0.0 (all tags) selected\n")
+	assert.Contains(t, out, fmt.Sprintf(`Base version: 1.0.0 at %s
+Commits
+├── %s feat: add search -> minor (conventional commit type feat)
+├── %s chore(deps): bump x -> skipped: ignored by the ignore config
+├── %s fix: flaky retry -> skipped: reverted by %s
+├── %s Revert "fix: flaky retry" -> skipped: reverts %s
+└── %s docs: explain search -> none (no bump rule matched)
+Bump: minor (highest commit bump)
+Next version: 1.1.0
+Calculated next version: 1.1.0
+`, short(base), short(feat), short(deps), short(fix), short(revert), short(revert), short(fix), short(docs)), out)
+}
+
+func TestExplainJSON(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "path-rules:\n  - paths: ['docs/**']\n    max: none\n")
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("docs/guide.md", "feat: document search")
+
+	var trace gitversion.Trace
+	require.NoError(t, json.Unmarshal([]byte(runExplain(t, repo, "json")), &trace))
+	assert.Equal(t, "1.0.0", trace.BaseVersion)
+	assert.Equal(t, "1.0.0", trace.NextVersion)
+	require.Len(t, trace.Commits, 1)
+	assert.Equal(t, "none", trace.Commits[0].Bump)
+	assert.Equal(t, "conventional commit type feat; path rules cap it at none, so it does not count as a change", trace.Commits[0].Rule)
+	assert.Equal(t, []gitversion.StrategyTrace{
+		{Name: "find-latest-tag", Ran: true, Outcome: "found base version 1.0.0"},
+		{Name: "increment-from-commits", Ran: true, Outcome: "produced no version"},
+		{Name: "configured-next-version", Ran: true, Outcome: "produced no version"},
+	}, trace.Strategies)
+}
+
+func TestExplainBreakingChangeFooter(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("b.txt", "fix: new output format\n\nBREAKING CHANGE: the output changed")
+
+	var trace gitversion.Trace
+	require.NoError(t, json.Unmarshal([]byte(runExplain(t, repo, "json")), &trace))
+	require.Len(t, trace.Commits, 1)
+	assert.Equal(t, "BREAKING CHANGE footer", trace.Commits[0].Rule)
+	assert.Equal(t, "2.0.0", trace.NextVersion)
+}
+
+func TestCalculateRecordsNoTraceWithoutExplain(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("b.txt", "feat: add search")
+
+	config, err := gitversion.LoadConfig(nil)
+	require.NoError(t, err)
+	result, err := gitversion.Calculate(repo.Repository, config, "master")
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", result.NextVersion.String())
+	assert.Nil(t, result.Trace)
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+func newGenerateRepo(t *testing.T) (*testRepo, string) {
+	repo := newTestRepo(t)
+	repo.writeFile("go.mod", "module example.com/m\n")
+	repo.tag("v1.2.3", repo.commit("initial commit"))
+	repo.writeFile("a.go", "package m")
+	when := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	head := repo.commitAs("feat: a feature", &object.Signature{Name: "Test", Email: "test@example.com", When: when})
+	return repo, head.String()
+}
+
+func TestGenerateGo(t *testing.T) {
+	repo, sha := newGenerateRepo(t)
+	outFile := filepath.Join(t.TempDir(), "version", "version_gen.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(outFile), 0755))
+
+	var out bytes.Buffer
+	err := app.RunGenerate(fs.NewOsFs(), &out, repo.path, app.GenerateOptions{Lang: "go", Out: outFile})
+	require.NoError(t, err)
+	assert.Equal(t, "Wrote "+outFile+"\n", out.String())
+
+	data, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`// Code generated by gitversion-go generate; DO NOT EDIT.
+
+package version
+
+// Version information of this build.
+const (
+	Major      = 1
+	Minor      = 3
+	Patch      = 0
+	FullSemVer = "1.3.0"
+	Sha        = %q
+	CommitDate = "2026-10-17T12:00:00Z"
+)
+`, sha), string(data))
+}
+
+func TestGenerateWritesOnlyOnChange(t *testing.T) {
+	repo, _ := newGenerateRepo(t)
+	outFile := filepath.Join(t.TempDir(), "VERSION")
+	opts := app.GenerateOptions{Lang: "VERSION", Out: outFile}
+
+	var out bytes.Buffer
+	require.NoError(t, app.RunGenerate(fs.NewOsFs(), &out, repo.path, opts))
+	data, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0\n", string(data))
+
+	stale := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(outFile, stale, stale))
+	out.Reset()
+	require.NoError(t, app.RunGenerate(fs.NewOsFs(), &out, repo.path, opts))
+	assert.Equal(t, outFile+" is up to date\n", out.String())
+	info, err := os.Stat(outFile)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(stale), "an unchanged file is not rewritten")
+}
+
+func TestGenerateJSON(t *testing.T) {
+	repo, sha := newGenerateRepo(t)
+	outFile := filepath.Join(t.TempDir(), "version.json")
+
+	var out bytes.Buffer
+	require.NoError(t, app.RunGenerate(fs.NewOsFs(), &out, repo.path, app.GenerateOptions{Lang: "json", Out: outFile}))
+	data, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, fmt.Sprintf(`{"Major": 1, "Minor": 3, "Patch": 0, "FullSemVer": "1.3.0", "Sha": %q, "CommitDate": "2026-10-17T12:00:00Z"}`, sha), string(data))
+}
+
+func TestGenerateResolvesOutAgainstRepository(t *testing.T) {
+	repo, _ := newGenerateRepo(t)
+	t.Chdir(t.TempDir())
+
+	var out bytes.Buffer
+	require.NoError(t, app.RunGenerate(fs.NewOsFs(), &out, repo.path, app.GenerateOptions{Lang: "VERSION", Out: "VERSION"}))
+	assert.Equal(t, "Wrote VERSION\n", out.String())
+	data, err := os.ReadFile(filepath.Join(repo.path, "VERSION"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0\n", string(data))
+	assert.NoFileExists(t, "VERSION")
+}
+
+func TestGenerateCreatesOutDirectory(t *testing.T) {
+	repo, _ := newGenerateRepo(t)
+
+	var out bytes.Buffer
+	require.NoError(t, app.RunGenerate(fs.NewOsFs(), &out, repo.path, app.GenerateOptions{Lang: "go", Out: "internal/version/version_gen.go"}))
+	assert.Equal(t, "Wrote internal/version/version_gen.go\n", out.String())
+	data, err := os.ReadFile(filepath.Join(repo.path, "internal", "version", "version_gen.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "package version\n")
+}
+
+func TestGenerateUnknownLanguage(t *testing.T) {
+	repo, _ := newGenerateRepo(t)
+	var out bytes.Buffer
+	err := app.RunGenerate(fs.NewOsFs(), &out, repo.path, app.GenerateOptions{Lang: "rust"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown language: rust")
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+func newGoModulesRepo(t *testing.T) *testRepo {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "go-modules: true\n")
+	repo.writeFile("go.mod", "module example.com/m\n\ngo 1.24\n")
+	repo.writeFile("tools/linter/go.mod", "module example.com/m/tools/linter\n\ngo 1.24\n")
+	repo.writeFile("v2/go.mod", "module example.com/m/v2\n\ngo 1.24\n")
+	repo.writeFile("testdata/go.mod", "module example.com/m/testdata\n\ngo 1.24\n")
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v1.0.0", initialCommit)
+	repo.tag("v2.1.0", initialCommit)
+	repo.tag("tools/linter/v1.2.3", initialCommit)
+	return repo
+}
+
+func calculateGoModules(t *testing.T, repo *testRepo) map[string]app.ProjectVersion {
+	t.Helper()
+	var out bytes.Buffer
+	err := app.RunCalculateWithOptions(fs.NewOsFs(), &out, repo.path, app.CalculateOptions{AllProjects: true})
+	require.NoError(t, err)
+
+	var projects []app.ProjectVersion
+	require.NoError(t, json.Unmarshal(out.Bytes(), &projects), out.String())
+	byName := make(map[string]app.ProjectVersion)
+	for _, p := range projects {
+		byName[p.Name] = p
+	}
+	return byName
+}
+
+func TestGoModulesDiscovery(t *testing.T) {
+	repo := newGoModulesRepo(t)
+	repo.writeFile("tools/linter/lint.go", "package linter")
+	repo.commit("fix: lint rule")
+
+	modules := calculateGoModules(t, repo)
+	require.Len(t, modules, 3, "testdata modules are skipped")
+
+	assert.Equal(t, "1.0.0", modules["."].Variables.FullSemVer, "changes in nested modules do not belong to the root module")
+	assert.Equal(t, "2.1.0", modules["v2"].Variables.FullSemVer, "a major version subdirectory shares the root tag prefix")
+	assert.Equal(t, "1.2.4", modules["tools/linter"].Variables.FullSemVer)
+	assert.Empty(t, modules["tools/linter"].Variables.Warnings)
+}
+
+func TestGoModulesMajorSuffixWarning(t *testing.T) {
+	repo := newGoModulesRepo(t)
+	repo.writeFile("tools/linter/lint.go", "package linter")
+	repo.commit("feat!: new rule format")
+
+	modules := calculateGoModules(t, repo)
+	linter := modules["tools/linter"]
+	assert.Equal(t, "2.0.0", linter.Variables.FullSemVer)
+	require.Len(t, linter.Variables.Warnings, 1)
+	assert.Contains(t, linter.Variables.Warnings[0], "the module path should be example.com/m/tools/linter/v2")
+}
+
+func TestGoModulesSingleProject(t *testing.T) {
+	repo := newGoModulesRepo(t)
+	repo.writeFile("main.go", "package main")
+	repo.commit("feat: root feature")
+
+	var out bytes.Buffer
+	err := app.RunCalculateWithOptions(fs.NewOsFs(), &out, repo.path, app.CalculateOptions{OutputFormat: "default", Project: "."})
+	require.NoError(t, err)
+	assert.Equal(t, "Calculated next version: 1.1.0\n", out.String())
+}
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+var goOutputTime = time.Date(2026, 10, 17, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+func calculateGoOutput(t *testing.T, repo *testRepo) string {
+	t.Helper()
+	var out bytes.Buffer
+	require.NoError(t, app.RunCalculate(fs.NewOsFs(), &out, repo.path, "go"))
+	return out.String()
+}
+
+func TestGoOutputPseudoVersion(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("go.mod", "module example.com/m\n")
+	repo.tag("v1.2.3", repo.commit("initial commit"))
+	repo.writeFile("a.go", "package m")
+	head := repo.commitAs("fix: a bug", &object.Signature{Name: "Test", Email: "test@example.com", When: goOutputTime})
+
+	sha := head.String()
+	assert.Equal(t, fmt.Sprintf("-ldflags \"-X main.Version=1.2.4 -X main.Commit=%s\"\nv1.2.4-0.20261017120000-%s\n", sha, sha[:12]),
+		calculateGoOutput(t, repo), "the commit time is converted to UTC")
+}
+
+func TestGoOutputConfiguredVariables(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "ldflags:\n  version: example.com/m/internal/build.Version\n  commit: example.com/m/internal/build.Revision\n")
+	head := repo.commitAs("initial commit", &object.Signature{Name: "Test", Email: "test@example.com", When: goOutputTime})
+
+	sha := head.String()
+	assert.Equal(t, fmt.Sprintf("-ldflags \"-X example.com/m/internal/build.Version=0.1.0 -X example.com/m/internal/build.Revision=%s\"\nv0.0.0-20261017120000-%s\n", sha, sha[:12]),
+		calculateGoOutput(t, repo), "without a base version the pseudo-version starts at v0.0.0")
+}
+
+func TestGoOutputPseudoVersionAfterPrerelease(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("go.mod", "module example.com/m\n")
+	repo.tag("v1.3.0-rc.1", repo.commit("initial commit"))
+	repo.writeFile("a.go", "package m")
+	head := repo.commitAs("fix: a bug", &object.Signature{Name: "Test", Email: "test@example.com", When: goOutputTime})
+
+	assert.Contains(t, calculateGoOutput(t, repo), "\nv1.3.0-rc.1.0.20261017120000-"+head.String()[:12]+"\n")
+}
+
+func TestGoOutputTaggedHead(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("go.mod", "module example.com/m\n")
+	repo.tag("v1.2.3", repo.commit("initial commit"))
+
+	assert.Contains(t, calculateGoOutput(t, repo), "\nv1.2.3\n")
+}
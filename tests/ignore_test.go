@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	testCases := []struct {
+		name            string
+		setupRepo       func(repo *testRepo)
+		expectedVersion string
+	}{
+		{
+			name: "ShortShaPrefix",
+			setupRepo: func(repo *testRepo) {
+				repo.writeFile("a.txt", "a")
+				ignored := repo.commit("feat: ignored")
+				repo.writeFile("GitVersion.yml", "ignore:\n  sha: ["+ignored.String()[:8]+"]")
+			},
+			expectedVersion: "1.0.0",
+		},
+		{
+			name: "AuthorEmail",
+			setupRepo: func(repo *testRepo) {
+				repo.writeFile("GitVersion.yml", "ignore:\n  author-emails: ['dependabot\\[bot\\]@users\\.noreply\\.github\\.com']")
+				repo.writeFile("go.sum", "bumped")
+				repo.commitAs("feat: bump deps", &object.Signature{Name: "dependabot[bot]", Email: "49699333+dependabot[bot]@users.noreply.github.com", When: time.Now()})
+			},
+			expectedVersion: "1.0.0",
+		},
+		{
+			name: "MessageRegex",
+			setupRepo: func(repo *testRepo) {
+				repo.writeFile("GitVersion.yml", "ignore:\n  messages: ['^feat\\(deps\\):']")
+				repo.writeFile("go.sum", "bumped")
+				repo.commit("feat(deps): bump deps")
+			},
+			expectedVersion: "1.0.0",
+		},
+		{
+			name: "PathsOnly",
+			setupRepo: func(repo *testRepo) {
+				repo.writeFile("GitVersion.yml", "ignore:\n  paths: ['docs/', '*.md']")
+				repo.commit("chore: config")
+				repo.writeFile("CHANGES.md", "notes")
+				repo.commit("feat: docs only")
+			},
+			expectedVersion: "1.0.1",
+		},
+		{
+			name: "PathsMixedChangeNotIgnored",
+			setupRepo: func(repo *testRepo) {
+				repo.writeFile("GitVersion.yml", "ignore:\n  paths: ['docs/', '*.md']")
+				repo.commit("chore: config")
+				repo.writeFile("CHANGES.md", "notes")
+				repo.writeFile("main.go", "package main")
+				repo.commit("feat: docs and code")
+			},
+			expectedVersion: "1.1.0",
+		},
+		{
+			name: "CommitsBefore",
+			setupRepo: func(repo *testRepo) {
+				repo.writeFile("GitVersion.yml", "ignore:\n  commits-before: 2020-01-01")
+				repo.writeFile("old.txt", "old")
+				repo.commitAs("feat: old", &object.Signature{Name: "Test", Email: "test@example.com", When: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)})
+				repo.writeFile("new.txt", "new")
+				repo.commitAs("fix: new", &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()})
+			},
+			expectedVersion: "1.0.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			repo.writeFile("README.txt", "initial commit")
+			initialCommit := repo.commit("initial commit")
+			repo.tag("v1.0.0", initialCommit)
+
+			tc.setupRepo(repo)
+
+			cmd := exec.Command(binaryPath, "calculate", "--path", repo.path)
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(output))
+
+			assert.Contains(t, string(output), "Calculated next version: "+tc.expectedVersion+"\n")
+		})
+	}
+}
+
+func TestIgnoreRules_InvalidRegex(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.txt", "initial commit")
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v1.0.0", initialCommit)
+	repo.writeFile("GitVersion.yml", "ignore:\n  messages: ['(']")
+	repo.commit("fix: something")
+
+	cmd := exec.Command(binaryPath, "calculate", "--path", repo.path)
+	output, err := cmd.CombinedOutput()
+	require.Error(t, err)
+	assert.Contains(t, string(output), "invalid ignore messages")
+}
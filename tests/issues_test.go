@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+func TestIssueReferencesDefaultPatterns(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("b.txt", "fix: crash on empty input (#12)\n\nFixes: JIRA-456\nRefs: GH-7")
+	repo.commitFile("c.txt", "feat: search\n\nCloses #12 and #13. See &#34; for HTML.")
+
+	vars := calculateJSON(t, repo)
+	assert.Equal(t, []app.Issue{
+		{ID: "12", Text: "#12"},
+		{ID: "JIRA-456", Text: "JIRA-456"},
+		{ID: "GH-7", Text: "GH-7"},
+		{ID: "13", Text: "#13"},
+	}, vars.Issues)
+}
+
+func TestIssueReferencesDefaultPatternsTakeJiraKeysFromTrailers(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("b.txt", "fix: read UTF-8 names for ABC-9\n\nEncode PEP-440 versions, as with GPT-4 and X-509.\n\nSigned-off-by: Test <test@example.com>\nRefs: ABC-10, ABC-11")
+	repo.commitFile("c.txt", "feat: handle COVID-19 data\n\nSee-also: ABC-12")
+
+	vars := calculateJSON(t, repo)
+	assert.Equal(t, []app.Issue{
+		{ID: "ABC-10", Text: "ABC-10"},
+		{ID: "ABC-11", Text: "ABC-11"},
+		{ID: "ABC-12", Text: "ABC-12"},
+	}, vars.Issues, "Jira-style keys outside issue trailers are not issues")
+}
+
+func TestIssueReferencesWithLinks(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", `issues:
+  - pattern: '#(\d+)'
+    text: 'GH #{id}'
+    url: https://github.com/acme/app/issues/{id}
+  - pattern: '\b(JIRA-\d+)\b'
+    url: https://jira.example.com/browse/{id}
+`)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	fix := repo.commitFile("b.txt", "fix: crash (#12)\n\nFixes: JIRA-456")
+	repo.commitFile("c.txt", "chore: bump deps for JIRA-789")
+
+	vars := calculateJSON(t, repo)
+	assert.Equal(t, []app.Issue{
+		{ID: "12", Text: "GH #12", URL: "https://github.com/acme/app/issues/12"},
+		{ID: "JIRA-456", Text: "JIRA-456", URL: "https://jira.example.com/browse/JIRA-456"},
+		{ID: "JIRA-789", Text: "JIRA-789", URL: "https://jira.example.com/browse/JIRA-789"},
+	}, vars.Issues, "commits outside the notes still count for release tracking")
+
+	assert.Equal(t, fmt.Sprintf(`## 1.0.1 (2026-10-17)
+
+### Bug Fixes
+
+- crash (#12) (%s), [GH #12](https://github.com/acme/app/issues/12) [JIRA-456](https://jira.example.com/browse/JIRA-456)
+`, short(fix)), runChangelog(t, repo, app.ChangelogOptions{}))
+}
+
+func TestIssueReferencesInvalidPattern(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "issues:\n  - pattern: '(['\n")
+	repo.commit("initial commit")
+
+	var out bytes.Buffer
+	err := app.RunCalculate(fs.NewOsFs(), &out, repo.path, "json")
+	assert.ErrorContains(t, err, "invalid issues[0] pattern")
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+const monorepoConfig = `
+projects:
+  billing:
+    path: services/billing
+    tag-prefix: 'billing/v'
+  auth:
+    path: services/auth
+    path-rules:
+      - paths: ['services/auth/api/']
+        min: minor
+`
+
+func newMonorepo(t *testing.T) *testRepo {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", monorepoConfig)
+	repo.writeFile("services/billing/main.go", "package main")
+	repo.writeFile("services/auth/main.go", "package main")
+	initialCommit := repo.commit("initial commit")
+	repo.tag("billing/v1.4.0", initialCommit)
+	repo.tag("auth/v2.0.1", initialCommit)
+	repo.tag("v9.0.0", initialCommit)
+	return repo
+}
+
+func calculateProject(t *testing.T, repo *testRepo, project string) string {
+	t.Helper()
+	cmd := exec.Command(binaryPath, "calculate", "--path", repo.path, "--project", project)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return string(output)
+}
+
+func TestMonorepoProjectScoping(t *testing.T) {
+	repo := newMonorepo(t)
+	repo.writeFile("services/billing/invoice.go", "package main")
+	repo.commit("feat: invoices")
+	repo.writeFile("services/auth/token.go", "package main")
+	repo.commit("fix: token expiry")
+
+	assert.Contains(t, calculateProject(t, repo, "billing"), "Calculated next version: 1.5.0\n")
+	assert.Contains(t, calculateProject(t, repo, "auth"), "Calculated next version: 2.0.2\n")
+}
+
+func TestMonorepoUntouchedProjectKeepsVersion(t *testing.T) {
+	repo := newMonorepo(t)
+	repo.writeFile("services/auth/token.go", "package main")
+	repo.commit("feat!: new token format")
+
+	assert.Contains(t, calculateProject(t, repo, "billing"), "Calculated next version: 1.4.0\n")
+	assert.Contains(t, calculateProject(t, repo, "auth"), "Calculated next version: 3.0.0\n")
+}
+
+func TestMonorepoProjectBumpRules(t *testing.T) {
+	repo := newMonorepo(t)
+	repo.writeFile("services/auth/api/v1.go", "package api")
+	repo.commit("fix: api tweak")
+
+	assert.Contains(t, calculateProject(t, repo, "auth"), "Calculated next version: 2.1.0\n")
+}
+
+func TestMonorepoUnknownProject(t *testing.T) {
+	repo := newMonorepo(t)
+
+	cmd := exec.Command(binaryPath, "calculate", "--path", repo.path, "--project", "payments")
+	output, err := cmd.CombinedOutput()
+	require.Error(t, err)
+	assert.Contains(t, string(output), "unknown project: payments")
+}
+
+func TestMonorepoAllProjects(t *testing.T) {
+	repo := newMonorepo(t)
+	repo.writeFile("services/billing/invoice.go", "package main")
+	repo.commit("feat: invoices")
+
+	var out bytes.Buffer
+	err := app.RunCalculateWithOptions(fs.NewOsFs(), &out, repo.path, app.CalculateOptions{AllProjects: true})
+	require.NoError(t, err)
+
+	var projects []app.ProjectVersion
+	require.NoError(t, json.Unmarshal(out.Bytes(), &projects), out.String())
+	require.Len(t, projects, 2)
+
+	assert.Equal(t, "auth", projects[0].Name)
+	assert.Equal(t, "services/auth", projects[0].Path)
+	assert.False(t, projects[0].Changed)
+	assert.Equal(t, "2.0.1", projects[0].Variables.FullSemVer)
+
+	assert.Equal(t, "billing", projects[1].Name)
+	assert.True(t, projects[1].Changed)
+	assert.Equal(t, "1.5.0", projects[1].Variables.FullSemVer)
+}
+
+func TestMonorepoSourceBranchIgnoresForeignTags(t *testing.T) {
+	repo := newMonorepo(t)
+	repo.checkout("develop")
+	repo.writeFile("services/auth/token.go", "package main")
+	repo.tag("v2.0.0", repo.commit("feat!: new token format"))
+
+	repo.checkout("feature/invoices")
+	repo.writeFile("GitVersion.yml", monorepoConfig+`
+branches:
+  ^feature/:
+    tag: use-branch-name
+    source-branches: [develop]
+`)
+	repo.writeFile("services/billing/invoice.go", "package main")
+	repo.commit("feat: invoices")
+
+	assert.Contains(t, calculateProject(t, repo, "billing"), "Calculated next version: 1.5.0-feature-invoices.1\n",
+		"an unprefixed tag on a source branch is not a billing version")
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+func calculateOCI(t *testing.T, repo *testRepo) app.OCIOutput {
+	t.Helper()
+	var out bytes.Buffer
+	require.NoError(t, app.RunCalculate(fs.NewOsFs(), &out, repo.path, "oci"))
+	var oci app.OCIOutput
+	require.NoError(t, json.Unmarshal(out.Bytes(), &oci), out.String())
+	return oci
+}
+
+func TestOCIStableReleaseTags(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "branches:\n  ^main$:\n    is-release-branch: true\n")
+	when := time.Date(2026, 10, 17, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	head := repo.commitAs("initial commit", &object.Signature{Name: "Test", Email: "test@example.com", When: when})
+	repo.tag("v1.2.3", head)
+	repo.checkout("main")
+
+	oci := calculateOCI(t, repo)
+	assert.Equal(t, []string{"1.2.3", "1.2", "1", "latest"}, oci.Tags)
+	assert.Equal(t, map[string]string{
+		"org.opencontainers.image.version":  "1.2.3",
+		"org.opencontainers.image.revision": head.String(),
+		"org.opencontainers.image.created":  "2026-10-17T12:00:00Z",
+	}, oci.Annotations)
+}
+
+func TestOCIBranchTags(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	repo.tag("v1.2.3", repo.commit("initial commit"))
+	repo.checkout("feature/Login_Page")
+	repo.writeFile("GitVersion.yml", "branches:\n  ^feature/.*$:\n    tag: alpha\n")
+	head := repo.commit("feat: login page")
+
+	oci := calculateOCI(t, repo)
+	assert.Equal(t, []string{"1.3.0-alpha.1", "feature-Login_Page-" + head.String()[:7]}, oci.Tags)
+	assert.Equal(t, "1.3.0-alpha.1", oci.Annotations["org.opencontainers.image.version"])
+}
+
+func TestOCIStableVersionOffReleaseBranch(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	head := repo.commit("initial commit")
+	repo.tag("v1.2.3", head)
+
+	oci := calculateOCI(t, repo)
+	assert.NotContains(t, oci.Tags, "latest", "latest is only for release branches")
+	assert.Equal(t, []string{"1.2.3", "master-" + head.String()[:7]}, oci.Tags)
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pathRulesConfig = `
+path-rules:
+  - paths: ['api/', 'proto/']
+    min: minor
+  - paths: ['docs/', '*.md']
+    max: none
+`
+
+func TestPathRules(t *testing.T) {
+	testCases := []struct {
+		name            string
+		files           map[string]string
+		commitMessage   string
+		expectedVersion string
+	}{
+		{"ApiChangeIsAtLeastMinor", map[string]string{"api/v1.go": "package api"}, "fix: api tweak", "1.1.0"},
+		{"ApiBreakingStaysMajor", map[string]string{"api/v1.go": "package api"}, "feat!: api rewrite", "2.0.0"},
+		{"DocsOnlyDoesNotBump", map[string]string{"README.md": "docs"}, "feat: document things", "1.0.0"},
+		{"DocsAndCodeBumps", map[string]string{"README.md": "docs", "main.go": "package main"}, "feat: code and docs", "1.1.0"},
+		{"MinimumWinsOverMaximum", map[string]string{"api/README.md": "docs"}, "docs: api docs", "1.1.0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			repo.writeFile("GitVersion.yml", pathRulesConfig)
+			initialCommit := repo.commit("initial commit")
+			repo.tag("v1.0.0", initialCommit)
+
+			for name, content := range tc.files {
+				repo.writeFile(name, content)
+			}
+			repo.commit(tc.commitMessage)
+
+			cmd := exec.Command(binaryPath, "calculate", "--path", repo.path)
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(output))
+
+			assert.Contains(t, string(output), "Calculated next version: "+tc.expectedVersion+"\n")
+		})
+	}
+}
+
+func TestPathRulesSkipMergesOfAnalysedCommits(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", `
+cherry-picks:
+  trailer: true
+ignore:
+  messages: ['^chore\(deps\)']
+path-rules:
+  - paths: ['api/']
+    min: minor
+`)
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v1.0.0", initialCommit)
+
+	repo.checkout("deps")
+	repo.writeFile("api/client.go", "package api")
+	deps := repo.commit("chore(deps): regenerate the api client")
+
+	// The merge brings in the ignored commit, whose api/ change must not come
+	// back through the diff of the merge.
+	repo.checkout("main")
+	require.NoError(t, repo.worktree.Reset(&git.ResetOptions{Commit: initialCommit, Mode: git.HardReset}))
+	repo.writeFile("main.go", "package main")
+	fix := repo.commit("fix: a bug")
+	repo.writeFile("api/client.go", "package api")
+	_, err := repo.worktree.Commit("Merge branch 'deps'", &git.CommitOptions{
+		Author:  &object.Signature{Name: "Test", Email: "test@example.com"},
+		Parents: []plumbing.Hash{fix, deps},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.0.1", calculateJSON(t, repo).FullSemVer)
+}
+
+func TestPathRules_InvalidBump(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "path-rules:\n  - paths: ['api/']\n    min: huge")
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v1.0.0", initialCommit)
+	repo.writeFile("main.go", "package main")
+	repo.commit("fix: something")
+
+	cmd := exec.Command(binaryPath, "calculate", "--path", repo.path)
+	output, err := cmd.CombinedOutput()
+	require.Error(t, err)
+	assert.Contains(t, string(output), `invalid path-rules[0] min: invalid bump "huge"`)
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+func runPromote(repo *testRepo, version string, opts app.PromoteOptions) (string, error) {
+	var out bytes.Buffer
+	err := app.RunPromote(fs.NewOsFs(), &out, repo.path, version, opts)
+	return out.String(), err
+}
+
+// newReleaseCandidates creates v1.3.0 followed by two release candidates of
+// 1.4.0 and an unreleased fix, and returns the commits of the candidates.
+func newReleaseCandidates(repo *testRepo) (rc1, rc2 plumbing.Hash) {
+	repo.tag("v1.3.0", repo.commitFile("a.txt", "initial commit"))
+	rc1 = repo.commitFile("b.txt", "feat: add search")
+	repo.tag("v1.4.0-rc.1", rc1)
+	rc2 = repo.commitFile("c.txt", "fix: search ranking")
+	repo.tag("v1.4.0-rc.2", rc2)
+	repo.commitFile("d.txt", "fix: not released yet")
+	return rc1, rc2
+}
+
+func TestPromoteTagsPreReleaseCommit(t *testing.T) {
+	repo := newTestRepo(t)
+	rc1, rc2 := newReleaseCandidates(repo)
+
+	out, err := runPromote(repo, "1.4.0-rc.2", app.PromoteOptions{Changelog: true})
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`Created tag v1.4.0
+## 1.4.0 (2026-10-17)
+
+### Features
+
+- add search (%s)
+
+### Bug Fixes
+
+- search ranking (%s)
+`, short(rc1), short(rc2)), out)
+
+	ref, err := repo.Tag("v1.4.0")
+	require.NoError(t, err)
+	assert.Equal(t, rc2, ref.Hash())
+}
+
+func TestPromoteRefusesLowerPreRelease(t *testing.T) {
+	repo := newTestRepo(t)
+	newReleaseCandidates(repo)
+
+	_, err := runPromote(repo, "1.4.0-rc.1", app.PromoteOptions{})
+	assert.EqualError(t, err, "failed to promote 1.4.0-rc.1: 1.4.0-rc.1 is not the highest pre-release of 1.4.0: 1.4.0-rc.2 is newer")
+}
+
+func TestPromoteRefusesReleasedVersion(t *testing.T) {
+	repo := newTestRepo(t)
+	newReleaseCandidates(repo)
+
+	_, err := runPromote(repo, "1.4.0-rc.2", app.PromoteOptions{})
+	require.NoError(t, err)
+	_, err = runPromote(repo, "1.4.0-rc.2", app.PromoteOptions{})
+	assert.EqualError(t, err, "failed to promote 1.4.0-rc.2: version 1.4.0 already exists as tag v1.4.0")
+}
+
+func TestPromoteRefusesFinalVersion(t *testing.T) {
+	repo := newTestRepo(t)
+	newReleaseCandidates(repo)
+
+	_, err := runPromote(repo, "1.3.0", app.PromoteOptions{})
+	assert.EqualError(t, err, "failed to promote 1.3.0: 1.3.0 is not a pre-release")
+	_, err = runPromote(repo, "1.5.0-rc.1", app.PromoteOptions{})
+	assert.EqualError(t, err, "failed to promote 1.5.0-rc.1: no tag found for version 1.5.0-rc.1")
+}
+
+func TestPromoteTagsNothingWhenNotesFail(t *testing.T) {
+	repo := newTestRepo(t)
+	newReleaseCandidates(repo)
+	require.NoError(t, os.Mkdir(filepath.Join(repo.path, "CHANGELOG.md"), 0755))
+
+	_, err := runPromote(repo, "1.4.0-rc.2", app.PromoteOptions{Update: filepath.Join(repo.path, "CHANGELOG.md")})
+	require.Error(t, err)
+	_, err = repo.Tag("v1.4.0")
+	assert.ErrorIs(t, err, git.ErrTagNotFound, "the tag is only created once the notes are ready")
+}
+
+func TestPromoteUpdatesChangelogInRepository(t *testing.T) {
+	repo := newTestRepo(t)
+	newReleaseCandidates(repo)
+	t.Chdir(t.TempDir())
+
+	out, err := runPromote(repo, "1.4.0-rc.2", app.PromoteOptions{Update: "CHANGELOG.md"})
+	require.NoError(t, err)
+	assert.Contains(t, out, "Updated CHANGELOG.md with 1.4.0\n")
+	assert.FileExists(t, filepath.Join(repo.path, "CHANGELOG.md"))
+	assert.NoFileExists(t, "CHANGELOG.md")
+}
+
+func TestPromoteRefusesYankedPreRelease(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "yanked:\n  - 1.4.0-rc.2\n")
+	newReleaseCandidates(repo)
+
+	_, err := runPromote(repo, "1.4.0-rc.2", app.PromoteOptions{})
+	assert.EqualError(t, err, "failed to promote 1.4.0-rc.2: version 1.4.0-rc.2 is retracted or yanked")
+}
+
+func TestPromoteWithRequiredSignedTags(t *testing.T) {
+	repo := newTestRepo(t)
+	keys := t.TempDir()
+	trusted := newSigningKey(t, keys, "release")
+	repo.writeFile("GitVersion.yml", "require-signed-tags: true\ntrusted-keys: "+filepath.Join(keys, "release.asc")+"\n")
+
+	repo.signedTag("v1.3.0", repo.commitFile("a.txt", "initial commit"), trusted)
+	fix := repo.commitFile("b.txt", "fix: crash on start")
+	repo.tag("v1.3.1", fix)
+	feat := repo.commitFile("c.txt", "feat: add search")
+	repo.signedTag("v1.4.0-rc.1", feat, trusted)
+	repo.tag("v1.4.0-rc.2", repo.commitFile("d.txt", "docs: usage"))
+
+	_, err := runPromote(repo, "1.4.0-rc.2", app.PromoteOptions{})
+	assert.EqualError(t, err, "failed to promote 1.4.0-rc.2: tag v1.4.0-rc.2 of version 1.4.0-rc.2 has no trusted signature")
+
+	out, err := runPromote(repo, "1.4.0-rc.1", app.PromoteOptions{Changelog: true})
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`Warning: ignoring tag v1.3.1: lightweight tags cannot be signed
+Warning: ignoring tag v1.4.0-rc.2: lightweight tags cannot be signed
+Created tag v1.4.0
+## 1.4.0 (2026-10-17)
+
+### Features
+
+- add search (%s)
+
+### Bug Fixes
+
+- crash on start (%s)
+`, short(feat), short(fix)), out, "the notes start at the last trusted release")
+}
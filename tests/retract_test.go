@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+func calculateDefault(t *testing.T, repo *testRepo) string {
+	t.Helper()
+	var out bytes.Buffer
+	require.NoError(t, app.RunCalculate(fs.NewOsFs(), &out, repo.path, "default"))
+	return out.String()
+}
+
+func TestRetractedBaseVersionIsSkipped(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("go.mod", "module example.com/m\n\ngo 1.24\n")
+	repo.tag("v1.3.0", repo.commit("initial commit"))
+	repo.writeFile("a.go", "package m")
+	repo.tag("v1.4.0", repo.commit("feat: broken feature"))
+	repo.writeFile("go.mod", "module example.com/m\n\ngo 1.24\n\nretract v1.4.0 // published by mistake\n")
+	repo.commit("fix: retract v1.4.0")
+
+	assert.Equal(t, "Warning: version 1.4.0 is retracted or yanked; using 1.5.0 instead\nCalculated next version: 1.5.0\n",
+		calculateDefault(t, repo), "the base is 1.3.0 and the next minor skips the retracted 1.4.0")
+}
+
+func TestRetractedRangeIsSkipped(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("go.mod", "module example.com/m\n\ngo 1.24\n\nretract [v1.0.1, v1.0.3]\n")
+	repo.tag("v1.0.0", repo.commit("initial commit"))
+	repo.writeFile("a.go", "package m")
+	repo.commit("fix: a bug")
+
+	assert.Contains(t, calculateDefault(t, repo), "Calculated next version: 1.0.4\n")
+}
+
+func TestYankedVersionsAreSkipped(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "yanked:\n  - 2.0.0\n  - 2.0.1\n")
+	initial := repo.commit("initial commit")
+	repo.tag("v1.0.0", initial)
+	repo.tag("2.0.0", initial)
+	repo.writeFile("a.go", "package m")
+	repo.commit("fix: a bug")
+
+	assert.Contains(t, calculateDefault(t, repo), "Calculated next version: 1.0.1\n", "the yanked 2.0.0 tag is not a base version")
+}
+
+func TestInvalidYankedVersion(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "yanked:\n  - not-a-version\n")
+	repo.commit("initial commit")
+
+	var out bytes.Buffer
+	err := app.RunCalculate(fs.NewOsFs(), &out, repo.path, "default")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid yanked version "not-a-version"`)
+}
+
+func TestWideRetractedRangeIsSkippedAtOnce(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("go.mod", "module example.com/m\n\ngo 1.24\n\nretract [v1.0.1, v1.999.0]\n")
+	repo.tag("v1.0.0", repo.commit("initial commit"))
+	repo.writeFile("a.go", "package m")
+	repo.commit("fix: a bug")
+
+	assert.Contains(t, calculateDefault(t, repo), "Calculated next version: 1.999.1\n")
+}
+
+func TestDependencyBumpSkipsYankedVersion(t *testing.T) {
+	repo := newDependencyRepo(t)
+	config, err := os.ReadFile(filepath.Join(repo.path, "GitVersion.yml"))
+	require.NoError(t, err)
+	repo.writeFile("GitVersion.yml", string(config)+"yanked: [0.3.1]\n")
+	repo.writeFile("libs/core/core.go", "package core")
+	repo.commit("feat: new core API")
+
+	byName := calculateProjectsByName(t, repo)
+	assert.Equal(t, "0.3.2", byName["api"].Variables.FullSemVer)
+	assert.Equal(t, []string{"version 0.3.1 is retracted or yanked; using 0.3.2 instead"}, byName["api"].Variables.Warnings)
+}
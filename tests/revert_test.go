@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type revertOutput struct {
+	FullSemVer       string
+	CancelledReverts []struct {
+		Revert   string
+		Reverted string
+	}
+}
+
+func calculateRevertOutput(t *testing.T, repo *testRepo) revertOutput {
+	t.Helper()
+	cmd := exec.Command(binaryPath, "calculate", "--path", repo.path, "--output", "json")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	var out revertOutput
+	require.NoError(t, json.Unmarshal(output, &out), string(output))
+	return out
+}
+
+func TestRevertCancelsBump(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v1.0.0", initialCommit)
+
+	repo.writeFile("a.txt", "a")
+	feat := repo.commit("feat: x")
+	repo.writeFile("b.txt", "b")
+	fix := repo.commit("fix: y")
+	repo.writeFile("a.txt", "reverted")
+	revert := repo.commit(fmt.Sprintf("Revert \"feat: x\"\n\nThis reverts commit %s.", feat))
+	_ = fix
+
+	out := calculateRevertOutput(t, repo)
+	assert.Equal(t, "1.0.1", out.FullSemVer)
+	require.Len(t, out.CancelledReverts, 1)
+	assert.Equal(t, revert.String(), out.CancelledReverts[0].Revert)
+	assert.Equal(t, feat.String(), out.CancelledReverts[0].Reverted)
+}
+
+func TestRevertOfRevertRestoresBump(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v1.0.0", initialCommit)
+
+	repo.writeFile("a.txt", "a")
+	feat := repo.commit("feat: x")
+	repo.writeFile("a.txt", "reverted")
+	revert := repo.commit(fmt.Sprintf("Revert \"feat: x\"\n\nThis reverts commit %s.", feat))
+	repo.writeFile("a.txt", "a")
+	repo.commit(fmt.Sprintf("Revert \"Revert \"feat: x\"\"\n\nThis reverts commit %s.", revert))
+
+	out := calculateRevertOutput(t, repo)
+	assert.Equal(t, "1.1.0", out.FullSemVer)
+	require.Len(t, out.CancelledReverts, 1)
+	assert.Equal(t, revert.String(), out.CancelledReverts[0].Reverted)
+}
+
+func TestRevertOfReleasedCommitIsNotPaired(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	feat := repo.commit("feat: x")
+	repo.tag("v1.1.0", feat)
+
+	repo.writeFile("README.md", "reverted")
+	repo.commit(fmt.Sprintf("Revert \"feat: x\"\n\nThis reverts commit %s.", feat))
+
+	out := calculateRevertOutput(t, repo)
+	assert.Equal(t, "1.1.1", out.FullSemVer)
+	assert.Empty(t, out.CancelledReverts)
+}
@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+// newSigningKey generates an OpenPGP key and writes its armored private key to
+// <name>.key and its public key to <name>.asc in dir.
+func newSigningKey(t *testing.T, dir, name string) *openpgp.Entity {
+	t.Helper()
+	key, err := openpgp.NewEntity(name, "", name+"@example.com", nil)
+	require.NoError(t, err)
+
+	var private, public bytes.Buffer
+	w, err := armor.Encode(&private, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, key.SerializePrivate(w, nil))
+	require.NoError(t, w.Close())
+	w, err = armor.Encode(&public, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, key.Serialize(w))
+	require.NoError(t, w.Close())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".key"), private.Bytes(), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".asc"), public.Bytes(), 0644))
+	return key
+}
+
+func (r *testRepo) signedTag(name string, hash plumbing.Hash, key *openpgp.Entity) {
+	_, err := r.CreateTag(name, hash, &git.CreateTagOptions{Message: name, Tagger: &object.Signature{Name: "Test", Email: "test@example.com", When: changelogTime}, SignKey: key})
+	require.NoError(r.t, err)
+}
+
+func TestTagSignsWithKeyFile(t *testing.T) {
+	repo := newTestRepo(t)
+	keys := t.TempDir()
+	newSigningKey(t, keys, "release")
+	repo.writeFile("GitVersion.yml", "tag:\n  signing-key: "+filepath.Join(keys, "release.key")+"\n")
+	setUser(t, repo)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("b.txt", "feat: add search")
+
+	_, err := runTag(repo, app.TagOptions{})
+	require.NoError(t, err)
+
+	ref, err := repo.Tag("v1.1.0")
+	require.NoError(t, err)
+	tag, err := repo.TagObject(ref.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, "Release v1.1.0\n", tag.Message)
+
+	public, err := os.ReadFile(filepath.Join(keys, "release.asc"))
+	require.NoError(t, err)
+	signer, err := tag.Verify(string(public))
+	require.NoError(t, err)
+	assert.Contains(t, signer.Identities, "release <release@example.com>")
+}
+
+func TestRequireSignedTagsSkipsUntrustedTags(t *testing.T) {
+	repo := newTestRepo(t)
+	keys := t.TempDir()
+	trusted := newSigningKey(t, keys, "release")
+	stranger := newSigningKey(t, keys, "stranger")
+	repo.writeFile("GitVersion.yml", "require-signed-tags: true\ntrusted-keys: "+filepath.Join(keys, "release.asc")+"\n")
+
+	repo.signedTag("v1.0.0", repo.commitFile("a.txt", "initial commit"), trusted)
+	repo.tag("v1.1.0", repo.commitFile("b.txt", "feat: add search"))
+	repo.signedTag("v1.2.0", repo.commitFile("c.txt", "feat: add filters"), stranger)
+	repo.commitFile("d.txt", "fix: handle empty input")
+
+	vars := calculateJSON(t, repo)
+	assert.Equal(t, "1.1.0", vars.FullSemVer)
+	require.Len(t, vars.Warnings, 2)
+	assert.Equal(t, "ignoring tag v1.1.0: lightweight tags cannot be signed", vars.Warnings[0])
+	assert.Contains(t, vars.Warnings[1], "ignoring tag v1.2.0: invalid signature:")
+}
+
+func TestRequireSignedTagsNeedsTrustedKeys(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "require-signed-tags: true\n")
+	repo.commitFile("a.txt", "initial commit")
+
+	err := app.RunCalculate(fs.NewOsFs(), &bytes.Buffer{}, repo.path, "json")
+	assert.EqualError(t, err, "failed to calculate next version: require-signed-tags needs trusted-keys or trusted-ssh-keys")
+}
+
+func TestRequireSignedTagsVerifiesOnlyCandidates(t *testing.T) {
+	repo := newTestRepo(t)
+	keys := t.TempDir()
+	trusted := newSigningKey(t, keys, "release")
+	repo.writeFile("GitVersion.yml", "require-signed-tags: true\ntrusted-keys: "+filepath.Join(keys, "release.asc")+"\n")
+
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.signedTag("v1.1.0", repo.commitFile("b.txt", "feat: add search"), trusted)
+	repo.commitFile("c.txt", "fix: handle empty input")
+
+	vars := calculateJSON(t, repo)
+	assert.Equal(t, "1.1.1", vars.FullSemVer)
+	assert.Empty(t, vars.Warnings, "tags below the selected version are not verified")
+}
+
+func TestRequireSignedTagsChecksChangelogRange(t *testing.T) {
+	repo := newTestRepo(t)
+	keys := t.TempDir()
+	trusted := newSigningKey(t, keys, "release")
+	repo.writeFile("GitVersion.yml", "require-signed-tags: true\ntrusted-keys: "+filepath.Join(keys, "release.asc")+"\nyanked: [1.1.1]\n")
+
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.signedTag("v1.1.0", repo.commitFile("b.txt", "feat: add search"), trusted)
+	repo.signedTag("v1.1.1", repo.commitFile("c.txt", "fix: handle empty input"), trusted)
+	repo.signedTag("v1.2.0", repo.commitFile("d.txt", "feat: add filters"), trusted)
+
+	err := app.RunChangelog(fs.NewOsFs(), &bytes.Buffer{}, repo.path, app.ChangelogOptions{From: "1.0.0", To: "1.1.0"})
+	assert.EqualError(t, err, "failed to calculate changelog: tag v1.0.0 of version 1.0.0 has no trusted signature")
+	err = app.RunChangelog(fs.NewOsFs(), &bytes.Buffer{}, repo.path, app.ChangelogOptions{From: "1.1.1", To: "1.2.0"})
+	assert.EqualError(t, err, "failed to calculate changelog: version 1.1.1 is retracted or yanked")
+	assert.NotEmpty(t, runChangelog(t, repo, app.ChangelogOptions{From: "1.1.0", To: "1.2.0"}))
+}
+
+func TestTagSignsWithSSHKey(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	repo := newTestRepo(t)
+	keyFile := filepath.Join(repo.path, "release.key")
+	require.NoError(t, exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "release", "-f", keyFile).Run())
+	public, err := os.ReadFile(keyFile + ".pub")
+	require.NoError(t, err)
+	allowed := filepath.Join(t.TempDir(), "allowed_signers")
+	require.NoError(t, os.WriteFile(allowed, []byte("release@example.com "+string(public)), 0644))
+
+	setUser(t, repo)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("b.txt", "feat: add search")
+	t.Chdir(t.TempDir())
+	_, err = runTag(repo, app.TagOptions{SigningKey: "release.key", SigningFormat: "ssh"})
+	require.NoError(t, err, "the key file is relative to the repository")
+
+	ref, err := repo.Tag("v1.1.0")
+	require.NoError(t, err)
+	tag, err := repo.TagObject(ref.Hash())
+	require.NoError(t, err)
+	assert.Contains(t, tag.PGPSignature, "-----BEGIN SSH SIGNATURE-----")
+
+	repo.writeFile("GitVersion.yml", "require-signed-tags: true\ntrusted-ssh-keys: "+allowed+"\n")
+	vars := calculateJSON(t, repo)
+	assert.Equal(t, "1.1.0", vars.FullSemVer)
+	assert.Empty(t, vars.Warnings)
+}
+
+func TestTagSignsWithGPGAgent(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg is not installed")
+	}
+	home, err := os.MkdirTemp("", "gnupg")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		exec.Command("gpgconf", "--kill", "gpg-agent").Run()
+		os.RemoveAll(home)
+	})
+	t.Setenv("GNUPGHOME", home)
+	require.NoError(t, exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", "release <release@example.com>", "ed25519", "sign", "never").Run())
+	public, err := exec.Command("gpg", "--export", "--armor", "release@example.com").Output()
+	require.NoError(t, err)
+	trusted := filepath.Join(t.TempDir(), "release.asc")
+	require.NoError(t, os.WriteFile(trusted, public, 0644))
+
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "tag:\n  signing-format: gpg\n  signing-key: release@example.com\n")
+	setUser(t, repo)
+	repo.commitFile("a.txt", "initial commit")
+	_, err = runTag(repo, app.TagOptions{})
+	require.NoError(t, err)
+
+	repo.writeFile("GitVersion.yml", "require-signed-tags: true\ntrusted-keys: "+trusted+"\n")
+	repo.commitFile("b.txt", "fix: handle empty input")
+	vars := calculateJSON(t, repo)
+	assert.Equal(t, "0.1.1", vars.FullSemVer)
+	assert.Empty(t, vars.Warnings)
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+func runTag(repo *testRepo, opts app.TagOptions) (string, error) {
+	var out bytes.Buffer
+	err := app.RunTag(fs.NewOsFs(), &out, repo.path, opts)
+	return out.String(), err
+}
+
+// setUser configures the tagger of annotated tags.
+func setUser(t *testing.T, repo *testRepo) {
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	cfg.User.Name = "Release Bot"
+	cfg.User.Email = "release@example.com"
+	require.NoError(t, repo.SetConfig(cfg))
+}
+
+func TestTagCreatesLightweightTag(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	head := repo.commitFile("b.txt", "feat: add search")
+
+	out, err := runTag(repo, app.TagOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Created tag v1.1.0\n", out)
+
+	ref, err := repo.Tag("v1.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, head, ref.Hash())
+}
+
+func TestTagKeepsPrefixOfBaseTag(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "tag-prefix: release-\n")
+	repo.tag("release-1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("b.txt", "fix: handle empty input")
+
+	out, err := runTag(repo, app.TagOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Created tag release-1.0.1\n", out)
+}
+
+func TestTagAnnotatedWithReleaseNotes(t *testing.T) {
+	repo := newTestRepo(t)
+	setUser(t, repo)
+
+	repo.writeFile("GitVersion.yml", `tag:
+  name: 'v{{.Major}}.{{.Minor}}.{{.Patch}}'
+  annotated: true
+  message: |
+    Release {{.Tag}}
+
+    {{.ReleaseNotes}}
+`)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	head := repo.commitFile("b.txt", "feat: add search")
+
+	_, err := runTag(repo, app.TagOptions{})
+	require.NoError(t, err)
+
+	ref, err := repo.Tag("v1.1.0")
+	require.NoError(t, err)
+	tag, err := repo.TagObject(ref.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, head, tag.Target)
+	assert.Equal(t, "Release Bot", tag.Tagger.Name)
+	assert.Equal(t, "Release v1.1.0\n\n## 1.1.0 (2026-10-17)\n\n### Features\n\n- add search ("+short(head)+")\n", tag.Message)
+}
+
+func TestTagRefusesTaggedHead(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+
+	_, err := runTag(repo, app.TagOptions{})
+	assert.EqualError(t, err, "HEAD is already tagged as v1.0.0")
+}
+
+func TestTagRefusesDirtyWorktree(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("b.txt", "feat: add search")
+	repo.writeFile("b.txt", "changed")
+
+	_, err := runTag(repo, app.TagOptions{})
+	assert.EqualError(t, err, "worktree has uncommitted changes: b.txt")
+}
+
+func TestTagRefusesExistingVersion(t *testing.T) {
+	repo := newTestRepo(t)
+	// A stale next-version setting yields a version that has already been released.
+	repo.writeFile("GitVersion.yml", "next-version: 1.0.0\nstrategies: [configured-next-version]\n")
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	repo.commitFile("b.txt", "fix: handle empty input")
+
+	_, err := runTag(repo, app.TagOptions{})
+	assert.EqualError(t, err, "version 1.0.0 already exists as tag v1.0.0")
+}
+
+func TestTagPushesToRemote(t *testing.T) {
+	repo := newTestRepo(t)
+	remoteDir := t.TempDir()
+	_, err := git.PlainInit(remoteDir, true)
+	require.NoError(t, err)
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: "backup", URLs: []string{remoteDir}})
+	require.NoError(t, err)
+
+	repo.tag("v1.0.0", repo.commitFile("a.txt", "initial commit"))
+	head := repo.commitFile("b.txt", "fix: handle empty input")
+
+	out, err := runTag(repo, app.TagOptions{Remote: "backup"})
+	require.NoError(t, err)
+	assert.Equal(t, "Created tag v1.0.1\nPushed tag v1.0.1 to backup\n", out)
+
+	remote, err := git.PlainOpen(remoteDir)
+	require.NoError(t, err)
+	ref, err := remote.Tag("v1.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, head, ref.Hash())
+}
@@ -1,10 +1,15 @@
 package tests
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
@@ -59,9 +64,28 @@ func (r *testRepo) checkout(branch string) {
 
 func (r *testRepo) writeFile(filename, content string) {
 	filePath := filepath.Join(r.path, filename)
-	err := os.WriteFile(filePath, []byte(content), 0644)
+	err := os.MkdirAll(filepath.Dir(filePath), 0755)
+	require.NoError(r.t, err)
+	err = os.WriteFile(filePath, []byte(content), 0644)
 	require.NoError(r.t, err)
 
 	_, err = r.worktree.Add(filename)
 	require.NoError(r.t, err)
 }
+
+func (r *testRepo) commitAs(msg string, author *object.Signature) plumbing.Hash {
+	commit, err := r.worktree.Commit(msg, &git.CommitOptions{Author: author})
+	require.NoError(r.t, err)
+	return commit
+}
+
+// calculateJSON runs calculate with JSON output and decodes the variables.
+func calculateJSON(t *testing.T, repo *testRepo) app.VersionVariables {
+	t.Helper()
+	var out bytes.Buffer
+	err := app.RunCalculate(fs.NewOsFs(), &out, repo.path, "json")
+	require.NoError(t, err)
+	var vars app.VersionVariables
+	require.NoError(t, json.Unmarshal(out.Bytes(), &vars), out.String())
+	return vars
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"encoding/json"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/gitversion"
+)
+
+func TestParseTrailers(t *testing.T) {
+	trailers := gitversion.ParseTrailers("feat: thing\n\nSome body text.\n\nRelease-As: 3.0.0\nSigned-off-by: Test <test@example.com>\n")
+	require.Len(t, trailers, 2)
+	assert.Equal(t, gitversion.Trailer{Key: "Release-As", Value: "3.0.0"}, trailers[0])
+
+	assert.Empty(t, gitversion.ParseTrailers("Release-As: 3.0.0"), "the subject line is not a trailer block")
+	assert.Empty(t, gitversion.ParseTrailers("feat: thing\n\nRelease-As: 3.0.0\nnot a trailer"))
+}
+
+func TestReleaseAsTrailer(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v1.0.0", initialCommit)
+
+	repo.writeFile("a.txt", "a")
+	releaseAs := repo.commit("chore: prepare major release\n\nRelease-As: 3.0.0")
+	repo.writeFile("b.txt", "b")
+	repo.commit("fix: a bug")
+
+	cmd := exec.Command(binaryPath, "calculate", "--path", repo.path, "--output", "json")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	var vars map[string]string
+	require.NoError(t, json.Unmarshal(output, &vars), string(output))
+	assert.Equal(t, "3.0.0", vars["FullSemVer"])
+	assert.Equal(t, releaseAs.String(), vars["ReleaseAsSource"])
+}
+
+func TestReleaseAsTrailer_BeatsNextVersion(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("GitVersion.yml", "next-version: 1.0.0")
+	repo.commit("chore: initial commit\n\nRelease-As: 2.0.0")
+
+	cmd := exec.Command(binaryPath, "calculate", "--path", repo.path)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	assert.Contains(t, string(output), "2.0.0")
+}
+
+func TestReleaseAsTrailer_IgnoredWhenNotAboveTag(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v2.0.0", initialCommit)
+
+	repo.writeFile("a.txt", "a")
+	repo.commit("fix: stale override\n\nRelease-As: 1.5.0")
+
+	cmd := exec.Command(binaryPath, "calculate", "--path", repo.path)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	assert.Contains(t, string(output), "2.0.1")
+}
+
+func TestSemverLabelTrailer(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v1.0.0", initialCommit)
+
+	repo.checkout("develop")
+	repo.writeFile("GitVersion.yml", `
+branches:
+  develop:
+    tag: alpha
+`)
+	labelCommit := repo.commit("feat: release candidate\n\nSemver-Label: rc")
+
+	cmd := exec.Command(binaryPath, "calculate", "--path", repo.path, "--output", "json")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	var vars map[string]string
+	require.NoError(t, json.Unmarshal(output, &vars), string(output))
+	assert.Equal(t, "1.1.0-rc.1", vars["FullSemVer"])
+	assert.Equal(t, labelCommit.String(), vars["SemverLabelSource"])
+}
+
+func TestTrailers_NewestWins(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.writeFile("README.md", "initial commit")
+	initialCommit := repo.commit("initial commit")
+	repo.tag("v1.0.0", initialCommit)
+
+	repo.checkout("develop")
+	repo.writeFile("GitVersion.yml", `
+branches:
+  develop:
+    tag: alpha
+`)
+	repo.writeFile("a.txt", "a")
+	repo.commit("chore: plan release\n\nRelease-As: 2.0.0\nSemver-Label: beta")
+	repo.writeFile("b.txt", "b")
+	newest := repo.commit("chore: replan release\n\nRelease-As: 3.0.0\nSemver-Label: rc")
+
+	cmd := exec.Command(binaryPath, "calculate", "--path", repo.path, "--output", "json")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	var vars map[string]string
+	require.NoError(t, json.Unmarshal(output, &vars), string(output))
+	assert.Equal(t, "3.0.0-rc.2", vars["FullSemVer"])
+	assert.Equal(t, newest.String(), vars["ReleaseAsSource"])
+	assert.Equal(t, newest.String(), vars["SemverLabelSource"])
+}
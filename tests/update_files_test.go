@@ -0,0 +1,233 @@
+package tests
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitversion-go/internal/app"
+	"gitversion-go/internal/fs"
+)
+
+const packageJSON = `{
+  "name": "app",
+  "version": "1.0.0",
+  "dependencies": {
+    "version": "^2.0.0"
+  }
+}
+`
+
+const chartYAML = `apiVersion: v2
+name: app
+# the chart version
+version: 1.0.0
+appVersion: "1.0.0"
+dependencies:
+  - name: redis
+    version: 17.0.0
+`
+
+const cargoTOML = `[package]
+name = "app"
+version = "1.0.0" # bumped by CI
+
+[dependencies]
+serde = { version = "1.0" }
+`
+
+const pomXML = `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <parent>
+    <version>3.1.0</version>
+  </parent>
+  <artifactId>app</artifactId>
+  <version>1.0.0</version>
+  <dependencies>
+    <dependency><version>2.0.0</version></dependency>
+  </dependencies>
+</project>
+`
+
+const pyprojectTOML = `[build-system]
+requires = ["hatchling"]
+
+[project]
+name = "app"
+version = '1.0.0'
+`
+
+// newUpdateFilesFs returns an in-memory filesystem holding the given files in the
+// repository's directory, which itself only holds the Git history.
+func newUpdateFilesFs(t *testing.T, repo *testRepo, files map[string]string) *fs.BillyWrappedFs {
+	fsys := fs.NewBillyWrappedFs(memfs.New())
+	for name, content := range files {
+		require.NoError(t, fsys.WriteFile(filepath.Join(repo.path, name), []byte(content), 0644))
+	}
+	return fsys
+}
+
+func newUpdateFilesRepo(t *testing.T) *testRepo {
+	repo := newTestRepo(t)
+	repo.writeFile("a.txt", "a")
+	repo.tag("v1.0.0", repo.commit("initial commit"))
+	repo.writeFile("b.txt", "b")
+	repo.commit("feat: a feature")
+	return repo
+}
+
+func readMemFile(t *testing.T, fsys *fs.BillyWrappedFs, repo *testRepo, name string) string {
+	t.Helper()
+	data, err := fsys.ReadFile(filepath.Join(repo.path, name))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestUpdateFilesManifests(t *testing.T) {
+	repo := newUpdateFilesRepo(t)
+	fsys := newUpdateFilesFs(t, repo, map[string]string{
+		"package.json":   packageJSON,
+		"Chart.yaml":     chartYAML,
+		"Cargo.toml":     cargoTOML,
+		"pom.xml":        pomXML,
+		"pyproject.toml": pyprojectTOML,
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, app.RunUpdateFiles(fsys, &out, repo.path, app.UpdateFilesOptions{}))
+	assert.Contains(t, out.String(), "Updated package.json to 1.1.0\n")
+
+	assert.Equal(t, `{
+  "name": "app",
+  "version": "1.1.0",
+  "dependencies": {
+    "version": "^2.0.0"
+  }
+}
+`, readMemFile(t, fsys, repo, "package.json"))
+	assert.Equal(t, `apiVersion: v2
+name: app
+# the chart version
+version: 1.1.0
+appVersion: "1.1.0"
+dependencies:
+  - name: redis
+    version: 17.0.0
+`, readMemFile(t, fsys, repo, "Chart.yaml"))
+	assert.Equal(t, `[package]
+name = "app"
+version = "1.1.0" # bumped by CI
+
+[dependencies]
+serde = { version = "1.0" }
+`, readMemFile(t, fsys, repo, "Cargo.toml"))
+	pom := readMemFile(t, fsys, repo, "pom.xml")
+	assert.Contains(t, pom, "<artifactId>app</artifactId>\n  <version>1.1.0</version>")
+	assert.Contains(t, pom, "<version>3.1.0</version>", "the parent version is left alone")
+	assert.Contains(t, pom, "<version>2.0.0</version>", "dependency versions are left alone")
+	assert.Contains(t, readMemFile(t, fsys, repo, "pyproject.toml"), "version = '1.1.0'\n")
+
+	out.Reset()
+	require.NoError(t, app.RunUpdateFiles(fsys, &out, repo.path, app.UpdateFilesOptions{}))
+	assert.Contains(t, out.String(), "package.json is up to date\n")
+}
+
+func TestUpdateFilesManifestEdgeCases(t *testing.T) {
+	repo := newUpdateFilesRepo(t)
+	fsys := newUpdateFilesFs(t, repo, map[string]string{
+		"Cargo.toml": `[package]
+name = "app"
+
+[[bin]]
+name = "tool"
+version = "9.9.9"
+
+[workspace.package]
+version = "1.0.0"
+`,
+		"pom.xml": "<project>\n  <artifactId>app</artifactId>\n  <version/>\n</project>\n",
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, app.RunUpdateFiles(fsys, &out, repo.path, app.UpdateFilesOptions{}))
+	assert.Equal(t, `[package]
+name = "app"
+
+[[bin]]
+name = "tool"
+version = "9.9.9"
+
+[workspace.package]
+version = "1.1.0"
+`, readMemFile(t, fsys, repo, "Cargo.toml"), "keys of an array of tables belong to no table")
+	assert.Equal(t, "<project>\n  <artifactId>app</artifactId>\n  <version>1.1.0</version>\n</project>\n", readMemFile(t, fsys, repo, "pom.xml"))
+}
+
+func TestUpdateFilesRulesAndDryRun(t *testing.T) {
+	repo := newUpdateFilesRepo(t)
+	fsys := newUpdateFilesFs(t, repo, map[string]string{
+		"GitVersion.yml":   "update-files:\n  files:\n    - web/package.json\n  rules:\n    - file: src/version.h\n      pattern: '#define APP_VERSION \"([^\"]*)\"'\n",
+		"web/package.json": packageJSON,
+		"src/version.h":    "#pragma once\n#define APP_VERSION \"1.0.0\"\n",
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, app.RunUpdateFiles(fsys, &out, repo.path, app.UpdateFilesOptions{DryRun: true}))
+	assert.Equal(t, `--- a/web/package.json
++++ b/web/package.json
+@@ -3 +3 @@
+-  "version": "1.0.0",
++  "version": "1.1.0",
+--- a/src/version.h
++++ b/src/version.h
+@@ -2 +2 @@
+-#define APP_VERSION "1.0.0"
++#define APP_VERSION "1.1.0"
+`, out.String())
+	assert.Equal(t, packageJSON, readMemFile(t, fsys, repo, "web/package.json"), "a dry run writes nothing")
+
+	require.NoError(t, app.RunUpdateFiles(fsys, &out, repo.path, app.UpdateFilesOptions{}))
+	assert.Equal(t, "#pragma once\n#define APP_VERSION \"1.1.0\"\n", readMemFile(t, fsys, repo, "src/version.h"))
+}
+
+func TestUpdateFilesDryRunWithMultilineRule(t *testing.T) {
+	repo := newUpdateFilesRepo(t)
+	fsys := newUpdateFilesFs(t, repo, map[string]string{
+		"GitVersion.yml": "update-files:\n  rules:\n    - file: version.txt\n      pattern: '(?s)version:(.*?)end'\n",
+		"version.txt":    "name: app\nversion:\n  1.0.0\nend\nlicense: MIT\n",
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, app.RunUpdateFiles(fsys, &out, repo.path, app.UpdateFilesOptions{DryRun: true}))
+	assert.Equal(t, `--- a/version.txt
++++ b/version.txt
+@@ -2,3 +2 @@
+-version:
+-  1.0.0
+-end
++version:1.1.0end
+`, out.String())
+}
+
+func TestUpdateFilesErrors(t *testing.T) {
+	repo := newUpdateFilesRepo(t)
+	var out bytes.Buffer
+
+	err := app.RunUpdateFiles(newUpdateFilesFs(t, repo, nil), &out, repo.path, app.UpdateFilesOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no files to update")
+
+	fsys := newUpdateFilesFs(t, repo, map[string]string{"package.json": `{"name": "app"}`})
+	err = app.RunUpdateFiles(fsys, &out, repo.path, app.UpdateFilesOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to update package.json: no top-level "version" found`)
+
+	fsys = newUpdateFilesFs(t, repo, map[string]string{"GitVersion.yml": "update-files:\n  files: [setup.py]\n"})
+	err = app.RunUpdateFiles(fsys, &out, repo.path, app.UpdateFilesOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported manifest setup.py")
+}